@@ -0,0 +1,326 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaintenancePhase contains the phase of maintenance
+type MaintenancePhase string
+
+const (
+	// MaintenanceRunning - maintenance has started its proccessing
+	MaintenanceRunning MaintenancePhase = "Running"
+	// MaintenanceSucceeded - maintenance has finished succesfuly, cordoned the node and evicted all pods (that could be evicted)
+	MaintenanceSucceeded MaintenancePhase = "Succeeded"
+	// MaintenanceFailed - maintenance has failed
+	MaintenanceFailed MaintenancePhase = "Failed"
+	// MaintenanceEnded - maintenance has been ended by setting its state to Inactive, the node is uncordoned
+	MaintenanceEnded MaintenancePhase = "Ended"
+	// MaintenanceWaitingForLease - maintenance waits for the expiry of a lease of the node held by another component
+	MaintenanceWaitingForLease MaintenancePhase = "WaitingForLease"
+)
+
+// MaintenanceState contains the desired state of maintenance
+// +kubebuilder:validation:Enum=Active;Inactive
+type MaintenanceState string
+
+const (
+	// MaintenanceActive - the node should be in maintenance
+	MaintenanceActive MaintenanceState = "Active"
+	// MaintenanceInactive - the node should not be in maintenance
+	MaintenanceInactive MaintenanceState = "Inactive"
+)
+
+// PDBPolicyType defines how PodDisruptionBudgets are handled by the drain
+// +kubebuilder:validation:Enum=Respect;BypassAfter
+type PDBPolicyType string
+
+const (
+	// PDBPolicyRespect - pods are only evicted, the drain waits for as long as PodDisruptionBudgets block the eviction
+	PDBPolicyRespect PDBPolicyType = "Respect"
+	// PDBPolicyBypassAfter - after the bypass deadline the remaining pods are deleted instead of evicted, ignoring PodDisruptionBudgets
+	PDBPolicyBypassAfter PDBPolicyType = "BypassAfter"
+)
+
+// PDBPolicy defines how PodDisruptionBudgets are handled by the drain
+type PDBPolicy struct {
+	// Type is the type of the policy (Respect,BypassAfter)
+	// +kubebuilder:default=Respect
+	Type PDBPolicyType `json:"type"`
+	// BypassAfter is the duration after the start of the maintenance, after which PodDisruptionBudgets are bypassed.
+	// Required for the BypassAfter policy.
+	// +optional
+	BypassAfter *metav1.Duration `json:"bypassAfter,omitempty"`
+}
+
+// DrainFailurePolicy defines what happens with the node when the drain didn't complete within the drain timeout
+// +kubebuilder:validation:Enum=KeepCordoned;Rollback
+type DrainFailurePolicy string
+
+const (
+	// DrainFailurePolicyKeepCordoned - the node stays cordoned and tainted, and its lease is kept
+	DrainFailurePolicyKeepCordoned DrainFailurePolicy = "KeepCordoned"
+	// DrainFailurePolicyRollback - the node is uncordoned and untainted, and its lease is released
+	DrainFailurePolicyRollback DrainFailurePolicy = "Rollback"
+)
+
+// FailureReason is the reason of a failed maintenance
+type FailureReason string
+
+const (
+	// FailureReasonDrainTimeout - the drain didn't complete within the drain timeout
+	FailureReasonDrainTimeout FailureReason = "DrainTimeout"
+	// FailureReasonLeaseRenewal - the lease of the node couldn't be renewed
+	FailureReasonLeaseRenewal FailureReason = "LeaseRenewalFailed"
+)
+
+const (
+	// ConditionDrained is True when the node is cordoned and all its pods, which can be evicted, were evicted
+	ConditionDrained = "Drained"
+	// ConditionFailed is True when the maintenance failed, its reason is the failure reason
+	ConditionFailed = "Failed"
+	// ConditionNodeUnreachable is True when the Ready condition of the node is Unknown
+	ConditionNodeUnreachable = "NodeUnreachable"
+)
+
+// PodEvictionReason is the reason why a pod wasn't evicted yet
+type PodEvictionReason string
+
+const (
+	// PodEvictionPDBBlocked - the eviction would violate a PodDisruptionBudget of the pod
+	PodEvictionPDBBlocked PodEvictionReason = "PDBBlocked"
+	// PodEvictionTooManyRequests - the eviction was rejected with 429 Too Many Requests for another reason than a PodDisruptionBudget
+	PodEvictionTooManyRequests PodEvictionReason = "TooManyRequests"
+	// PodEvictionServerError - the eviction failed with 500 Internal Server Error, e.g. because the pod has multiple PodDisruptionBudgets
+	PodEvictionServerError PodEvictionReason = "ServerError"
+	// PodEvictionTerminating - the pod was evicted, but didn't terminate yet
+	PodEvictionTerminating PodEvictionReason = "Terminating"
+	// PodEvictionPending - the eviction is allowed, but wasn't done yet
+	PodEvictionPending PodEvictionReason = "Pending"
+	// PodEvictionFailed - the eviction failed with another error
+	PodEvictionFailed PodEvictionReason = "Failed"
+)
+
+// PodReference references a pod
+type PodReference struct {
+	// Namespace is the namespace of the pod, it is empty for pods which were only recorded by name
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the pod
+	Name string `json:"name"`
+}
+
+// PodEvictionStatus is the eviction status of a pod of the node which wasn't evicted yet
+type PodEvictionStatus struct {
+	// Pod references the pod
+	Pod PodReference `json:"pod"`
+	// OwnerKind is the kind of the controller of the pod, if any
+	OwnerKind string `json:"ownerKind,omitempty"`
+	// OwnerName is the name of the controller of the pod, if any
+	OwnerName string `json:"ownerName,omitempty"`
+	// Attempts is the number of drain attempts which didn't evict the pod
+	Attempts int `json:"attempts"`
+	// Reason is the reason why the pod wasn't evicted yet (PDBBlocked,TooManyRequests,ServerError,Terminating,Pending,Failed)
+	Reason PodEvictionReason `json:"reason"`
+	// LastError is the error of the latest eviction attempt, if any
+	LastError string `json:"lastError,omitempty"`
+	// FirstAttemptTime is the time of the first drain attempt which didn't evict the pod
+	FirstAttemptTime metav1.Time `json:"firstAttemptTime"`
+	// LastAttemptTime is the time of the latest drain attempt which didn't evict the pod
+	LastAttemptTime metav1.Time `json:"lastAttemptTime"`
+}
+
+// PhaseTransition records the time the maintenance entered a phase
+type PhaseTransition struct {
+	// Phase is the phase the maintenance entered
+	Phase MaintenancePhase `json:"phase"`
+	// Time is the time the maintenance entered the phase
+	Time metav1.Time `json:"time"`
+}
+
+// ForeignLease describes a lease of the node which is held by another component
+type ForeignLease struct {
+	// HolderIdentity is the identity of the holder of the lease
+	HolderIdentity string `json:"holderIdentity"`
+	// AcquireTime is the time the lease was acquired by its holder
+	AcquireTime *metav1.Time `json:"acquireTime,omitempty"`
+	// ExpiryTime is the time the lease expires, unless it is renewed by its holder
+	ExpiryTime metav1.Time `json:"expiryTime"`
+}
+
+// DrainSpec defines how the node is drained
+type DrainSpec struct {
+	// Timeout is the maximum duration of the drain since the start of the maintenance.
+	// The maintenance fails when the drain didn't complete in time. Without it the drain is retried forever.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// FailurePolicy defines what happens with the node when the drain didn't complete within the timeout (KeepCordoned,Rollback).
+	// Defaults to the failure policy configured in the operator, or KeepCordoned.
+	// +optional
+	FailurePolicy DrainFailurePolicy `json:"failurePolicy,omitempty"`
+	// PDBPolicy defines how PodDisruptionBudgets are handled by the drain, they are respected by default.
+	// Bypassing them is meant for maintenances which must proceed in any case, e.g. because of failed hardware.
+	// +optional
+	PDBPolicy *PDBPolicy `json:"pdbPolicy,omitempty"`
+	// ForceDeleteTerminatingPodsAfter enables force deleting the pods of an unreachable node, which are terminating for longer than the given duration.
+	// Pods of unreachable nodes can't terminate, because their kubelet can't confirm that their containers were stopped.
+	// Only use it when the containers of the node are known to be stopped, e.g. because the node is powered off,
+	// since their replacements might run concurrently otherwise.
+	// +optional
+	ForceDeleteTerminatingPodsAfter *metav1.Duration `json:"forceDeleteTerminatingPodsAfter,omitempty"`
+}
+
+// NodeMaintenanceSpec defines the desired state of NodeMaintenance
+type NodeMaintenanceSpec struct {
+	// NodeName is the name of the node to put into maintenance
+	NodeName string `json:"nodeName"`
+	// Reason is the reason of the maintenance
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// State is the desired state of the maintenance (Active,Inactive).
+	// Setting it to Inactive ends the maintenance without deleting the NodeMaintenance, setting it back to Active restarts it.
+	// +kubebuilder:default=Active
+	// +optional
+	State MaintenanceState `json:"state,omitempty"`
+	// Drain defines how the node is drained
+	// +optional
+	Drain *DrainSpec `json:"drain,omitempty"`
+}
+
+// ConfigMapReference references a ConfigMap
+type ConfigMapReference struct {
+	// Namespace is the namespace of the ConfigMap
+	Namespace string `json:"namespace"`
+	// Name is the name of the ConfigMap
+	Name string `json:"name"`
+}
+
+// PodsStatus is the status of the pods of the node
+type PodsStatus struct {
+	// Total is the total number of all pods on the node from the start
+	Total int `json:"total,omitempty"`
+	// ToEvict is the total number of pods up for eviction from the start
+	ToEvict int `json:"toEvict,omitempty"`
+	// PendingCount is the number of pending pods for eviction
+	PendingCount int `json:"pendingCount,omitempty"`
+	// EvictedCount is the number of pods which were evicted so far
+	EvictedCount int `json:"evictedCount,omitempty"`
+	// SkippedCount is the number of pods on the node which are not evicted, e.g. DaemonSet pods
+	SkippedCount int `json:"skippedCount,omitempty"`
+	// FailedCount is the number of pending pods whose latest eviction attempt failed, among the pods of Evictions
+	FailedCount int `json:"failedCount,omitempty"`
+	// Pending is a list of pending pods for eviction, capped at a maximum number of pods, see PendingCount
+	Pending []PodReference `json:"pending,omitempty"`
+	// Evicted is a list of pods which were evicted so far, capped at a maximum number of pods, see EvictedCount
+	Evicted []PodReference `json:"evicted,omitempty"`
+	// ForceDeleted is a list of pods of the unreachable node which were force deleted, see spec.drain.forceDeleteTerminatingPodsAfter
+	ForceDeleted []PodReference `json:"forceDeleted,omitempty"`
+	// ListConfigMap references the ConfigMap with the complete lists of pending and evicted pods, if enabled in the operator
+	ListConfigMap *ConfigMapReference `json:"listConfigMap,omitempty"`
+	// Evictions is the eviction status of the pods which weren't evicted by the latest drain attempt,
+	// capped at a maximum number of pods
+	Evictions []PodEvictionStatus `json:"evictions,omitempty"`
+}
+
+// LeaseStatus is the status of the lease of the node
+type LeaseStatus struct {
+	// ExpiryTime is the time the lease of the node expires, unless it is renewed.
+	// The lease is renewed before it expires, as long as the maintenance is active.
+	ExpiryTime *metav1.Time `json:"expiryTime,omitempty"`
+	// ErrorCount is the consecutive number of errors upon obtaining the lease
+	ErrorCount int `json:"errorCount,omitempty"`
+	// Foreign describes the lease of the node held by another component, which the maintenance is waiting for
+	Foreign *ForeignLease `json:"foreign,omitempty"`
+}
+
+// NodeMaintenanceStatus defines the observed state of NodeMaintenance
+type NodeMaintenanceStatus struct {
+	// Phase is the represtation of the maintenance progress (Running,Succeeded,Failed,Ended,WaitingForLease)
+	Phase MaintenancePhase `json:"phase,omitempty"`
+	// Conditions are the Drained, Failed and NodeUnreachable conditions of the maintenance
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// LastError represents the latest error if any in the latest reconciliation
+	LastError string `json:"lastError,omitempty"`
+	// FailureReason is the reason of the Failed phase (DrainTimeout,LeaseRenewalFailed)
+	FailureReason FailureReason `json:"failureReason,omitempty"`
+	// RolledBack is true when the node was taken out of maintenance because of the Rollback failure policy
+	RolledBack bool `json:"rolledBack,omitempty"`
+	// NodeCordoned is true while the node is cordoned and tainted by the maintenance.
+	// It stays true when the maintenance waits for the lease of the node after it was taken over by another component.
+	NodeCordoned bool `json:"nodeCordoned,omitempty"`
+	// Pods is the status of the pods of the node
+	// +optional
+	Pods *PodsStatus `json:"pods,omitempty"`
+	// Lease is the status of the lease of the node
+	// +optional
+	Lease *LeaseStatus `json:"lease,omitempty"`
+	// StartTime is the time the maintenance was started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// DrainStartTime is the time the drain of the node started, after its lease was obtained.
+	// The drain timeout is measured from this time.
+	DrainStartTime *metav1.Time `json:"drainStartTime,omitempty"`
+	// DrainCompletedTime is the time all pods were evicted for the first time
+	DrainCompletedTime *metav1.Time `json:"drainCompletedTime,omitempty"`
+	// EndTime is the time the maintenance was ended
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// LastReconcileTime is the time of the latest reconciliation
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+	// PDBBypassTime is the time the pods of the node started to be deleted without eviction,
+	// after the bypass deadline of the PDB policy
+	PDBBypassTime *metav1.Time `json:"pdbBypassTime,omitempty"`
+	// ViolatedPDBs is a list of PodDisruptionBudgets which were violated by deleting pods after the bypass deadline of the PDB policy
+	ViolatedPDBs []string `json:"violatedPDBs,omitempty"`
+	// PhaseTransitions is the timeline of the latest phase changes, oldest first
+	PhaseTransitions []PhaseTransition `json:"phaseTransitions,omitempty"`
+}
+
+//+genclient
+//+genclient:nonNamespaced
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NodeMaintenance is the Schema for the nodemaintenances API
+type NodeMaintenance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeMaintenanceSpec   `json:"spec,omitempty"`
+	Status NodeMaintenanceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NodeMaintenanceList contains a list of NodeMaintenance
+type NodeMaintenanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeMaintenance `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeMaintenance{}, &NodeMaintenanceList{})
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AdmissionCheckAction defines how the validating webhook handles NodeMaintenances which fail an admission check
+type AdmissionCheckAction string
+
+const (
+	// AdmissionCheckIgnore skips the check
+	AdmissionCheckIgnore AdmissionCheckAction = "Ignore"
+	// AdmissionCheckWarn allows the NodeMaintenance with an admission warning, which is printed by kubectl
+	AdmissionCheckWarn AdmissionCheckAction = "Warn"
+	// AdmissionCheckDeny rejects the NodeMaintenance
+	AdmissionCheckDeny AdmissionCheckAction = "Deny"
+)
+
+const (
+	WarningLastNodeInZone = "node %s is the last schedulable node in zone %s, its pods can't be rescheduled within the zone during the maintenance"
+	WarningSingletonPods  = "node %s hosts the only replica of %s, it is unavailable during the maintenance"
+	WarningLocalVolumes   = "node %s hosts %s with local persistent volumes, they can't be rescheduled to other nodes during the maintenance"
+	WarningCheckFailed    = "could not check node %s for %s: %v"
+
+	// maxPodsInMessage is the maximum number of pods named in the message of a failed admission check
+	maxPodsInMessage = 5
+)
+
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims;persistentvolumes,verbs=get;list;watch
+
+// ParseAdmissionCheckAction parses the given action case insensitively
+func ParseAdmissionCheckAction(action string) (AdmissionCheckAction, error) {
+	for _, a := range []AdmissionCheckAction{AdmissionCheckIgnore, AdmissionCheckWarn, AdmissionCheckDeny} {
+		if strings.EqualFold(action, string(a)) {
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("invalid admission check action %q, must be one of %s, %s or %s",
+		action, AdmissionCheckIgnore, AdmissionCheckWarn, AdmissionCheckDeny)
+}
+
+// admissionCheck checks whether putting a node into maintenance is risky.
+// It returns an empty message if it isn't.
+type admissionCheck func(node *v1.Node) (string, error)
+
+// runAdmissionChecks runs the configured admission checks for the given node.
+// It returns the messages of the failed checks with the Warn action as warnings,
+// and the message of the first failed check with the Deny action as error.
+func (v *NodeMaintenanceValidator) runAdmissionChecks(nodeName string) ([]string, error) {
+	checks := []struct {
+		name   string
+		action AdmissionCheckAction
+		check  admissionCheck
+	}{
+		{"last node in zone", webhookConfig.LastNodeInZoneCheck, v.checkLastNodeInZone},
+		{"singleton pods", webhookConfig.SingletonPodsCheck, v.checkSingletonPods},
+		{"local volumes", webhookConfig.LocalVolumesCheck, v.checkLocalVolumes},
+	}
+
+	var node *v1.Node
+	var warnings []string
+	for _, c := range checks {
+		if c.action != AdmissionCheckWarn && c.action != AdmissionCheckDeny {
+			continue
+		}
+		if node == nil {
+			var err error
+			if node, err = getNode(nodeName, v.client); err != nil {
+				return warnings, fmt.Errorf("could not get node for admission checks, please try again: %v", err)
+			} else if node == nil {
+				return warnings, fmt.Errorf(ErrorNodeNotExists, nodeName)
+			}
+		}
+
+		message, err := c.check(node)
+		if err != nil {
+			if c.action == AdmissionCheckDeny {
+				return warnings, fmt.Errorf("%s, please try again", fmt.Sprintf(WarningCheckFailed, nodeName, c.name, err))
+			}
+			warnings = append(warnings, fmt.Sprintf(WarningCheckFailed, nodeName, c.name, err))
+			continue
+		}
+		if message == "" {
+			continue
+		}
+		if c.action == AdmissionCheckDeny {
+			return warnings, errors.New(message)
+		}
+		warnings = append(warnings, message)
+	}
+	return warnings, nil
+}
+
+// checkLastNodeInZone checks whether the node is the last schedulable node of its zone
+func (v *NodeMaintenanceValidator) checkLastNodeInZone(node *v1.Node) (string, error) {
+	zone := node.Labels[v1.LabelTopologyZone]
+	if zone == "" {
+		return "", nil
+	}
+
+	nodes := &v1.NodeList{}
+	if err := v.client.List(context.TODO(), nodes, client.MatchingLabels{v1.LabelTopologyZone: zone}); err != nil {
+		return "", fmt.Errorf("could not list nodes of zone %s: %v", zone, err)
+	}
+	for i := range nodes.Items {
+		if nodes.Items[i].Name != node.Name && isSchedulable(&nodes.Items[i]) {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf(WarningLastNodeInZone, node.Name, zone), nil
+}
+
+// checkSingletonPods checks whether the node hosts pods of ReplicaSets or StatefulSets with a single replica
+func (v *NodeMaintenanceValidator) checkSingletonPods(node *v1.Node) (string, error) {
+	pods, err := v.listActivePods(node.Name)
+	if err != nil {
+		return "", err
+	}
+
+	var singletons []string
+	for i := range pods {
+		pod := &pods[i]
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil || !strings.HasPrefix(owner.APIVersion, appsv1.GroupName+"/") {
+			continue
+		}
+		var replicas *int32
+		key := types.NamespacedName{Namespace: pod.Namespace, Name: owner.Name}
+		switch owner.Kind {
+		case "ReplicaSet":
+			rs := &appsv1.ReplicaSet{}
+			if err := v.client.Get(context.TODO(), key, rs); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return "", fmt.Errorf("could not get ReplicaSet %s: %v", key, err)
+			}
+			replicas = rs.Spec.Replicas
+		case "StatefulSet":
+			sts := &appsv1.StatefulSet{}
+			if err := v.client.Get(context.TODO(), key, sts); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return "", fmt.Errorf("could not get StatefulSet %s: %v", key, err)
+			}
+			replicas = sts.Spec.Replicas
+		default:
+			continue
+		}
+		// unset replicas default to 1
+		if replicas == nil || *replicas <= 1 {
+			singletons = append(singletons, pod.Namespace+"/"+pod.Name)
+		}
+	}
+
+	if len(singletons) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(WarningSingletonPods, node.Name, formatPods(singletons)), nil
+}
+
+// checkLocalVolumes checks whether the node hosts pods which use local or host path persistent volumes
+func (v *NodeMaintenanceValidator) checkLocalVolumes(node *v1.Node) (string, error) {
+	pods, err := v.listActivePods(node.Name)
+	if err != nil {
+		return "", err
+	}
+
+	var localPods []string
+	for i := range pods {
+		pod := &pods[i]
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			local, err := v.isLocalVolumeClaim(pod.Namespace, volume.PersistentVolumeClaim.ClaimName)
+			if err != nil {
+				return "", err
+			}
+			if local {
+				localPods = append(localPods, pod.Namespace+"/"+pod.Name)
+				break
+			}
+		}
+	}
+
+	if len(localPods) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(WarningLocalVolumes, node.Name, formatPods(localPods)), nil
+}
+
+// isLocalVolumeClaim returns true if the given PersistentVolumeClaim is bound to a local or host path PersistentVolume
+func (v *NodeMaintenanceValidator) isLocalVolumeClaim(namespace, claimName string) (bool, error) {
+	pvc := &v1.PersistentVolumeClaim{}
+	if err := v.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: claimName}, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not get PersistentVolumeClaim %s/%s: %v", namespace, claimName, err)
+	}
+	if pvc.Spec.VolumeName == "" {
+		return false, nil
+	}
+
+	pv := &v1.PersistentVolume{}
+	if err := v.client.Get(context.TODO(), types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not get PersistentVolume %s: %v", pvc.Spec.VolumeName, err)
+	}
+	return pv.Spec.Local != nil || pv.Spec.HostPath != nil, nil
+}
+
+// listActivePods lists the pods of the node which aren't terminated, with the pod index of the cache by node name
+func (v *NodeMaintenanceValidator) listActivePods(nodeName string) ([]v1.Pod, error) {
+	podList := &v1.PodList{}
+	if err := v.client.List(context.TODO(), podList, client.MatchingFields{NodeNameField: nodeName}); err != nil {
+		return nil, fmt.Errorf("could not list pods: %v", err)
+	}
+	var pods []v1.Pod
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != nodeName || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// isSchedulable returns true if the node is ready and not cordoned
+func isSchedulable(node *v1.Node) bool {
+	return !node.Spec.Unschedulable && isNodeReady(node)
+}
+
+// formatPods joins the given pod names for a message, naming at most maxPodsInMessage pods
+func formatPods(pods []string) string {
+	prefix := "pod"
+	if len(pods) > 1 {
+		prefix = "pods"
+	}
+	if len(pods) > maxPodsInMessage {
+		return fmt.Sprintf("%s %s and %d more", prefix, strings.Join(pods[:maxPodsInMessage], ", "), len(pods)-maxPodsInMessage)
+	}
+	return fmt.Sprintf("%s %s", prefix, strings.Join(pods, ", "))
+}
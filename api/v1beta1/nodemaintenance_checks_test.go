@@ -0,0 +1,178 @@
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("NodeMaintenance Admission Checks", func() {
+
+	const nodeName = "node-checked"
+	const zone = "zone-a"
+
+	var checkValidator *NodeMaintenanceValidator
+
+	newNode := func(name string, ready bool) *v1.Node {
+		node := getTestNode(name, false)
+		node.Labels = map[string]string{v1.LabelTopologyZone: zone}
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		node.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: status}}
+		return node
+	}
+
+	newPod := func(name string, owner *metav1.OwnerReference) *v1.Pod {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       v1.PodSpec{NodeName: nodeName},
+		}
+		if owner != nil {
+			pod.OwnerReferences = []metav1.OwnerReference{*owner}
+		}
+		return pod
+	}
+
+	newReplicaSet := func(name string, replicas int32) (*appsv1.ReplicaSet, *metav1.OwnerReference) {
+		rs := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       appsv1.ReplicaSetSpec{Replicas: pointer.Int32Ptr(replicas)},
+		}
+		owner := metav1.NewControllerRef(rs, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))
+		return rs, owner
+	}
+
+	setup := func(config WebhookConfig, objs ...client.Object) {
+		checkValidator = newFakeValidator(objs...)
+		SetWebhookConfig(config)
+	}
+
+	AfterEach(func() {
+		SetWebhookConfig(WebhookConfig{})
+	})
+
+	It("should parse admission check actions", func() {
+		Expect(ParseAdmissionCheckAction("warn")).To(Equal(AdmissionCheckWarn))
+		Expect(ParseAdmissionCheckAction("Deny")).To(Equal(AdmissionCheckDeny))
+		Expect(ParseAdmissionCheckAction("IGNORE")).To(Equal(AdmissionCheckIgnore))
+		_, err := ParseAdmissionCheckAction("block")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("for the last schedulable node in a zone", func() {
+
+		It("should warn", func() {
+			setup(WebhookConfig{LastNodeInZoneCheck: AdmissionCheckWarn}, newNode(nodeName, true), newNode("node-not-ready", false))
+			warnings, err := checkValidator.ValidateCreate(getTestNMO(nodeName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("last schedulable node in zone %s", zone)))
+		})
+
+		It("should deny", func() {
+			setup(WebhookConfig{LastNodeInZoneCheck: AdmissionCheckDeny}, newNode(nodeName, true))
+			_, err := checkValidator.ValidateCreate(getTestNMO(nodeName))
+			Expect(err).To(MatchError(ContainSubstring("last schedulable node in zone %s", zone)))
+		})
+
+		It("should be ignored", func() {
+			setup(WebhookConfig{LastNodeInZoneCheck: AdmissionCheckIgnore}, newNode(nodeName, true))
+			Expect(checkValidator.ValidateCreate(getTestNMO(nodeName))).To(BeEmpty())
+		})
+
+		It("should not warn with other schedulable nodes in the zone", func() {
+			setup(WebhookConfig{LastNodeInZoneCheck: AdmissionCheckDeny}, newNode(nodeName, true), newNode("node-ready", true))
+			Expect(checkValidator.ValidateCreate(getTestNMO(nodeName))).To(BeEmpty())
+		})
+	})
+
+	Context("for nodes hosting singleton pods", func() {
+
+		It("should warn about pods of ReplicaSets with a single replica only", func() {
+			singletonRS, singletonOwner := newReplicaSet("singleton", 1)
+			scaledRS, scaledOwner := newReplicaSet("scaled", 3)
+			setup(WebhookConfig{SingletonPodsCheck: AdmissionCheckWarn}, newNode(nodeName, true),
+				singletonRS, scaledRS, newPod("singleton-pod", singletonOwner), newPod("scaled-pod", scaledOwner), newPod("bare-pod", nil))
+
+			warnings, err := checkValidator.ValidateCreate(getTestNMO(nodeName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("the only replica of pod default/singleton-pod,")))
+		})
+
+		It("should not check inactive NodeMaintenances", func() {
+			rs, owner := newReplicaSet("singleton", 1)
+			setup(WebhookConfig{SingletonPodsCheck: AdmissionCheckDeny}, newNode(nodeName, true), rs, newPod("singleton-pod", owner))
+			nm := getTestNMO(nodeName)
+			nm.Spec.State = MaintenanceInactive
+			Expect(checkValidator.ValidateCreate(nm)).To(BeEmpty())
+		})
+	})
+
+	Context("for nodes hosting pods with local volumes", func() {
+
+		It("should warn about pods with local persistent volumes only", func() {
+			localPV := &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "local-pv"},
+				Spec: v1.PersistentVolumeSpec{
+					PersistentVolumeSource: v1.PersistentVolumeSource{Local: &v1.LocalVolumeSource{Path: "/mnt/disk"}},
+				},
+			}
+			remotePV := &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "remote-pv"},
+				Spec: v1.PersistentVolumeSpec{
+					PersistentVolumeSource: v1.PersistentVolumeSource{NFS: &v1.NFSVolumeSource{Server: "nfs", Path: "/"}},
+				},
+			}
+			newPVC := func(name, volumeName string) *v1.PersistentVolumeClaim {
+				return &v1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+					Spec:       v1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+				}
+			}
+			withClaim := func(pod *v1.Pod, claimName string) *v1.Pod {
+				pod.Spec.Volumes = []v1.Volume{{
+					Name:         "data",
+					VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claimName}},
+				}}
+				return pod
+			}
+			setup(WebhookConfig{LocalVolumesCheck: AdmissionCheckWarn}, newNode(nodeName, true),
+				localPV, remotePV, newPVC("local-pvc", localPV.Name), newPVC("remote-pvc", remotePV.Name),
+				withClaim(newPod("local-pod", nil), "local-pvc"), withClaim(newPod("remote-pod", nil), "remote-pvc"))
+
+			warnings, err := checkValidator.ValidateCreate(getTestNMO(nodeName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("hosts pod default/local-pod with local persistent volumes")))
+		})
+	})
+
+	It("should return the warnings in the admission response", func() {
+		setup(WebhookConfig{LastNodeInZoneCheck: AdmissionCheckWarn}, newNode(nodeName, true))
+		raw, err := json.Marshal(getTestNMO(nodeName))
+		Expect(err).NotTo(HaveOccurred())
+
+		response := checkValidator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Warnings).To(ConsistOf(ContainSubstring("last schedulable node in zone %s", zone)))
+	})
+
+	It("should format long pod lists", func() {
+		Expect(formatPods([]string{"a/1"})).To(Equal("pod a/1"))
+		Expect(formatPods([]string{"a/1", "a/2", "a/3", "a/4", "a/5", "a/6", "a/7"})).To(Equal("pods a/1, a/2, a/3, a/4, a/5 and 2 more"))
+	})
+})
@@ -0,0 +1,404 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v1 "kubevirt.io/node-maintenance-operator/api/v1"
+)
+
+const (
+	// ConditionReasonDrainCompleted is the reason of the True Drained condition
+	ConditionReasonDrainCompleted = "DrainCompleted"
+	// ConditionReasonNodeReadyUnknown is the reason of the True NodeUnreachable condition
+	ConditionReasonNodeReadyUnknown = "NodeReadyUnknown"
+	// ConditionReasonNodeReachable is the reason of the False NodeUnreachable condition
+	ConditionReasonNodeReachable = "NodeReachable"
+
+	// ConditionsAnnotation keeps the v1 conditions of a NodeMaintenance served as v1beta1,
+	// so that their transition times survive a round trip through v1beta1
+	ConditionsAnnotation = "nodemaintenance.kubevirt.io/v1-conditions"
+)
+
+var _ conversion.Convertible = &NodeMaintenance{}
+
+// ConvertTo converts this NodeMaintenance to the v1 hub version.
+// The conditions of the v1 status are derived from the v1beta1 status.
+func (r *NodeMaintenance) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1.NodeMaintenance)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub %T", dstRaw)
+	}
+	dst.ObjectMeta = r.ObjectMeta
+	dst.Annotations = withoutAnnotation(r.Annotations, ConditionsAnnotation)
+
+	dst.Spec = v1.NodeMaintenanceSpec{
+		NodeName: r.Spec.NodeName,
+		Reason:   r.Spec.Reason,
+		State:    v1.MaintenanceState(r.Spec.State),
+	}
+	if r.Spec.DrainTimeout != nil || r.Spec.FailurePolicy != "" || r.Spec.PDBPolicy != nil || r.Spec.ForceDeleteTerminatingPodsAfter != nil {
+		dst.Spec.Drain = &v1.DrainSpec{
+			Timeout:                         r.Spec.DrainTimeout,
+			FailurePolicy:                   v1.DrainFailurePolicy(r.Spec.FailurePolicy),
+			ForceDeleteTerminatingPodsAfter: r.Spec.ForceDeleteTerminatingPodsAfter,
+		}
+		if r.Spec.PDBPolicy != nil {
+			dst.Spec.Drain.PDBPolicy = &v1.PDBPolicy{
+				Type:        v1.PDBPolicyType(r.Spec.PDBPolicy.Type),
+				BypassAfter: r.Spec.PDBPolicy.BypassAfter,
+			}
+		}
+	}
+
+	status := &r.Status
+	dst.Status = v1.NodeMaintenanceStatus{
+		Phase:              v1.MaintenancePhase(status.Phase),
+		LastError:          status.LastError,
+		FailureReason:      v1.FailureReason(status.FailureReason),
+		RolledBack:         status.RolledBack,
+		NodeCordoned:       status.NodeCordoned,
+		StartTime:          status.StartTime,
+		DrainStartTime:     status.DrainStartTime,
+		DrainCompletedTime: status.DrainCompletedTime,
+		EndTime:            status.EndTime,
+		LastReconcileTime:  status.LastReconcileTime,
+		PDBBypassTime:      status.PDBBypassTime,
+		ViolatedPDBs:       status.ViolatedPDBs,
+		Conditions:         r.getConditions(r.getStoredConditions()),
+	}
+	for _, transition := range status.PhaseTransitions {
+		dst.Status.PhaseTransitions = append(dst.Status.PhaseTransitions, v1.PhaseTransition{
+			Phase: v1.MaintenancePhase(transition.Phase),
+			Time:  transition.Time,
+		})
+	}
+
+	pods := &v1.PodsStatus{
+		Total:        status.TotalPods,
+		ToEvict:      status.EvictionPods,
+		PendingCount: status.PendingPodsCount,
+		EvictedCount: status.EvictedPodsCount,
+		SkippedCount: status.SkippedPodsCount,
+		FailedCount:  status.FailedPodsCount,
+		Pending:      toPodReferences(status.PendingPods),
+		Evicted:      toPodReferences(status.EvictedPods),
+		ForceDeleted: toPodReferences(status.ForceDeletedPods),
+	}
+	if status.PodListConfigMap != nil {
+		pods.ListConfigMap = &v1.ConfigMapReference{
+			Namespace: status.PodListConfigMap.Namespace,
+			Name:      status.PodListConfigMap.Name,
+		}
+	}
+	for _, eviction := range status.PodEvictions {
+		pods.Evictions = append(pods.Evictions, v1.PodEvictionStatus{
+			Pod:              v1.PodReference{Namespace: eviction.Namespace, Name: eviction.Name},
+			OwnerKind:        eviction.OwnerKind,
+			OwnerName:        eviction.OwnerName,
+			Attempts:         eviction.Attempts,
+			Reason:           v1.PodEvictionReason(eviction.Reason),
+			LastError:        eviction.LastError,
+			FirstAttemptTime: eviction.FirstAttemptTime,
+			LastAttemptTime:  eviction.LastAttemptTime,
+		})
+	}
+	if !reflect.DeepEqual(*pods, v1.PodsStatus{}) {
+		dst.Status.Pods = pods
+	}
+
+	if status.LeaseExpiryTime != nil || status.ErrorOnLeaseCount != 0 || status.ForeignLease != nil {
+		dst.Status.Lease = &v1.LeaseStatus{
+			ExpiryTime: status.LeaseExpiryTime,
+			ErrorCount: status.ErrorOnLeaseCount,
+		}
+		if status.ForeignLease != nil {
+			dst.Status.Lease.Foreign = &v1.ForeignLease{
+				HolderIdentity: status.ForeignLease.HolderIdentity,
+				AcquireTime:    status.ForeignLease.AcquireTime,
+				ExpiryTime:     status.ForeignLease.ExpiryTime,
+			}
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1 hub version to this NodeMaintenance.
+// The NodeUnreachable condition is the only condition which is converted to a status field,
+// conditions which can't be derived from the status are kept in the conditions annotation.
+func (r *NodeMaintenance) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1.NodeMaintenance)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub %T", srcRaw)
+	}
+	r.ObjectMeta = src.ObjectMeta
+
+	r.Spec = NodeMaintenanceSpec{
+		NodeName: src.Spec.NodeName,
+		Reason:   src.Spec.Reason,
+		State:    MaintenanceState(src.Spec.State),
+	}
+	if drain := src.Spec.Drain; drain != nil {
+		r.Spec.DrainTimeout = drain.Timeout
+		r.Spec.FailurePolicy = DrainFailurePolicy(drain.FailurePolicy)
+		r.Spec.ForceDeleteTerminatingPodsAfter = drain.ForceDeleteTerminatingPodsAfter
+		if drain.PDBPolicy != nil {
+			r.Spec.PDBPolicy = &PDBPolicy{
+				Type:        PDBPolicyType(drain.PDBPolicy.Type),
+				BypassAfter: drain.PDBPolicy.BypassAfter,
+			}
+		}
+	}
+
+	status := &src.Status
+	r.Status = NodeMaintenanceStatus{
+		Phase:              MaintenancePhase(status.Phase),
+		LastError:          status.LastError,
+		FailureReason:      FailureReason(status.FailureReason),
+		RolledBack:         status.RolledBack,
+		NodeCordoned:       status.NodeCordoned,
+		StartTime:          status.StartTime,
+		DrainStartTime:     status.DrainStartTime,
+		DrainCompletedTime: status.DrainCompletedTime,
+		EndTime:            status.EndTime,
+		LastReconcileTime:  status.LastReconcileTime,
+		PDBBypassTime:      status.PDBBypassTime,
+		ViolatedPDBs:       status.ViolatedPDBs,
+		NodeUnreachable:    meta.IsStatusConditionTrue(status.Conditions, v1.ConditionNodeUnreachable),
+	}
+	for _, transition := range status.PhaseTransitions {
+		r.Status.PhaseTransitions = append(r.Status.PhaseTransitions, PhaseTransition{
+			Phase: MaintenancePhase(transition.Phase),
+			Time:  transition.Time,
+		})
+	}
+
+	if pods := status.Pods; pods != nil {
+		r.Status.TotalPods = pods.Total
+		r.Status.EvictionPods = pods.ToEvict
+		r.Status.PendingPodsCount = pods.PendingCount
+		r.Status.EvictedPodsCount = pods.EvictedCount
+		r.Status.SkippedPodsCount = pods.SkippedCount
+		r.Status.FailedPodsCount = pods.FailedCount
+		r.Status.PendingPods = fromPodReferences(pods.Pending)
+		r.Status.EvictedPods = fromPodReferences(pods.Evicted)
+		r.Status.ForceDeletedPods = fromPodReferences(pods.ForceDeleted)
+		if pods.ListConfigMap != nil {
+			r.Status.PodListConfigMap = &ConfigMapReference{
+				Namespace: pods.ListConfigMap.Namespace,
+				Name:      pods.ListConfigMap.Name,
+			}
+		}
+		for _, eviction := range pods.Evictions {
+			r.Status.PodEvictions = append(r.Status.PodEvictions, PodEvictionStatus{
+				Namespace:        eviction.Pod.Namespace,
+				Name:             eviction.Pod.Name,
+				OwnerKind:        eviction.OwnerKind,
+				OwnerName:        eviction.OwnerName,
+				Attempts:         eviction.Attempts,
+				Reason:           PodEvictionReason(eviction.Reason),
+				LastError:        eviction.LastError,
+				FirstAttemptTime: eviction.FirstAttemptTime,
+				LastAttemptTime:  eviction.LastAttemptTime,
+			})
+		}
+	}
+
+	if lease := status.Lease; lease != nil {
+		r.Status.LeaseExpiryTime = lease.ExpiryTime
+		r.Status.ErrorOnLeaseCount = lease.ErrorCount
+		if lease.Foreign != nil {
+			r.Status.ForeignLease = &ForeignLease{
+				HolderIdentity: lease.Foreign.HolderIdentity,
+				AcquireTime:    lease.Foreign.AcquireTime,
+				ExpiryTime:     lease.Foreign.ExpiryTime,
+			}
+		}
+	}
+	return r.storeConditions(status.Conditions)
+}
+
+// storeConditions stores the v1 conditions in the conditions annotation, unless they are derived from the status anyway
+func (r *NodeMaintenance) storeConditions(conditions []metav1.Condition) error {
+	r.Annotations = withoutAnnotation(r.Annotations, ConditionsAnnotation)
+	if equality.Semantic.DeepEqual(conditions, r.getConditions(nil)) {
+		return nil
+	}
+	value, err := json.Marshal(conditions)
+	if err != nil {
+		return fmt.Errorf("failed to store conditions: %v", err)
+	}
+	// the annotations are shared with the source object, so they must be copied before they are modified
+	annotations := make(map[string]string, len(r.Annotations)+1)
+	for key, annotation := range r.Annotations {
+		annotations[key] = annotation
+	}
+	annotations[ConditionsAnnotation] = string(value)
+	r.Annotations = annotations
+	return nil
+}
+
+// getStoredConditions returns the v1 conditions of the conditions annotation.
+// An invalid annotation is ignored, the conditions are derived from the status then.
+func (r *NodeMaintenance) getStoredConditions() []metav1.Condition {
+	value, found := r.Annotations[ConditionsAnnotation]
+	if !found {
+		return nil
+	}
+	var conditions []metav1.Condition
+	if err := json.Unmarshal([]byte(value), &conditions); err != nil {
+		nodemaintenancelog.Error(err, "ignoring invalid conditions annotation", "name", r.Name)
+		return nil
+	}
+	return conditions
+}
+
+// withoutAnnotation returns a copy of the annotations without the given annotation, or the annotations themselves if they don't have it
+func withoutAnnotation(annotations map[string]string, annotation string) map[string]string {
+	if _, found := annotations[annotation]; !found {
+		return annotations
+	}
+	copied := make(map[string]string, len(annotations)-1)
+	for key, value := range annotations {
+		if key != annotation {
+			copied[key] = value
+		}
+	}
+	if len(copied) == 0 {
+		return nil
+	}
+	return copied
+}
+
+// getConditions returns the v1 conditions of the maintenance, or nil if it didn't start yet.
+// The stored conditions are updated with the status, they keep their transition time as long as their status doesn't change.
+// Without stored conditions, they transitioned when the current phase was entered, as far as it is known.
+func (r *NodeMaintenance) getConditions(stored []metav1.Condition) []metav1.Condition {
+	status := &r.Status
+	if status.Phase == "" {
+		return nil
+	}
+	phaseTime := r.getPhaseTime()
+
+	drained := metav1.Condition{
+		Type:               v1.ConditionDrained,
+		Status:             metav1.ConditionFalse,
+		Reason:             string(status.Phase),
+		Message:            fmt.Sprintf("%d pods pending for eviction", status.PendingPodsCount),
+		LastTransitionTime: phaseTime,
+	}
+	if status.Phase == MaintenanceSucceeded {
+		drained.Status = metav1.ConditionTrue
+		drained.Reason = ConditionReasonDrainCompleted
+		drained.Message = fmt.Sprintf("%d pods evicted", status.EvictedPodsCount)
+	}
+
+	failed := metav1.Condition{
+		Type:               v1.ConditionFailed,
+		Status:             metav1.ConditionFalse,
+		Reason:             string(status.Phase),
+		LastTransitionTime: phaseTime,
+	}
+	if status.Phase == MaintenanceFailed {
+		failed.Status = metav1.ConditionTrue
+		failed.Reason = string(MaintenanceFailed)
+		if status.FailureReason != "" {
+			failed.Reason = string(status.FailureReason)
+		}
+		failed.Message = status.LastError
+	}
+
+	unreachable := metav1.Condition{
+		Type:               v1.ConditionNodeUnreachable,
+		Status:             metav1.ConditionFalse,
+		Reason:             ConditionReasonNodeReachable,
+		LastTransitionTime: phaseTime,
+	}
+	if status.NodeUnreachable {
+		unreachable.Status = metav1.ConditionTrue
+		unreachable.Reason = ConditionReasonNodeReadyUnknown
+	}
+	if meta.FindStatusCondition(stored, v1.ConditionNodeUnreachable) != nil {
+		// the reachability of the node doesn't change with the phase, a change of its status happened just now
+		unreachable.LastTransitionTime = metav1.Time{}
+	}
+
+	conditions := make([]metav1.Condition, 0, len(stored)+3)
+	for _, condition := range stored {
+		conditions = append(conditions, *condition.DeepCopy())
+	}
+	for _, condition := range []metav1.Condition{drained, failed, unreachable} {
+		meta.SetStatusCondition(&conditions, condition)
+	}
+	return conditions
+}
+
+// getPhaseTime returns the time the current phase was entered, as far as it is known
+func (r *NodeMaintenance) getPhaseTime() metav1.Time {
+	status := &r.Status
+	for i := len(status.PhaseTransitions) - 1; i >= 0; i-- {
+		if status.PhaseTransitions[i].Phase == status.Phase {
+			return status.PhaseTransitions[i].Time
+		}
+	}
+	if status.StartTime != nil {
+		return *status.StartTime
+	}
+	return r.CreationTimestamp
+}
+
+// toPodReferences converts pod names, optionally prefixed with the namespace of the pod and a slash, to pod references
+func toPodReferences(pods []string) []v1.PodReference {
+	if pods == nil {
+		return nil
+	}
+	refs := make([]v1.PodReference, 0, len(pods))
+	for _, pod := range pods {
+		ref := v1.PodReference{Name: pod}
+		if i := strings.Index(pod, "/"); i >= 0 {
+			ref.Namespace = pod[:i]
+			ref.Name = pod[i+1:]
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// fromPodReferences converts pod references to pod names, prefixed with the namespace of the pod and a slash if it is known
+func fromPodReferences(refs []v1.PodReference) []string {
+	if refs == nil {
+		return nil
+	}
+	pods := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Namespace != "" {
+			pods = append(pods, ref.Namespace+"/"+ref.Name)
+			continue
+		}
+		pods = append(pods, ref.Name)
+	}
+	return pods
+}
@@ -0,0 +1,209 @@
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodemaintenancev1 "kubevirt.io/node-maintenance-operator/api/v1"
+)
+
+var _ = Describe("NodeMaintenance Conversion", func() {
+
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	later := metav1.NewTime(now.Add(time.Minute))
+
+	getFullNM := func() *NodeMaintenance {
+		nm := getTestNMO("node01")
+		nm.Spec = NodeMaintenanceSpec{
+			NodeName:                        "node01",
+			Reason:                          "test",
+			State:                           MaintenanceActive,
+			ForceDeleteTerminatingPodsAfter: &metav1.Duration{Duration: time.Minute},
+			PDBPolicy:                       &PDBPolicy{Type: PDBPolicyBypassAfter, BypassAfter: &metav1.Duration{Duration: time.Hour}},
+			DrainTimeout:                    &metav1.Duration{Duration: 2 * time.Hour},
+			FailurePolicy:                   DrainFailurePolicyRollback,
+		}
+		nm.Status = NodeMaintenanceStatus{
+			Phase:             MaintenanceRunning,
+			LastError:         "some pods pending",
+			PendingPods:       []string{"pod-a"},
+			EvictedPods:       []string{"pod-b"},
+			PendingPodsCount:  1,
+			EvictedPodsCount:  1,
+			SkippedPodsCount:  2,
+			FailedPodsCount:   1,
+			PodListConfigMap:  &ConfigMapReference{Namespace: "ns", Name: "cm"},
+			TotalPods:         4,
+			EvictionPods:      2,
+			ErrorOnLeaseCount: 1,
+			NodeCordoned:      true,
+			PodEvictions: []PodEvictionStatus{{
+				Namespace:        "default",
+				Name:             "pod-a",
+				OwnerKind:        "ReplicaSet",
+				OwnerName:        "rs",
+				Attempts:         3,
+				Reason:           PodEvictionPDBBlocked,
+				LastError:        "disruption budget",
+				FirstAttemptTime: now,
+				LastAttemptTime:  later,
+			}},
+			StartTime:         &now,
+			DrainStartTime:    &now,
+			LastReconcileTime: &later,
+			LeaseExpiryTime:   &later,
+			ForeignLease:      &ForeignLease{HolderIdentity: "other", AcquireTime: &now, ExpiryTime: later},
+			NodeUnreachable:   true,
+			ForceDeletedPods:  []string{"default/pod-c"},
+			PDBBypassTime:     &later,
+			ViolatedPDBs:      []string{"default/pdb"},
+			PhaseTransitions:  []PhaseTransition{{Phase: MaintenanceRunning, Time: now}},
+		}
+		return nm
+	}
+
+	It("should convert to v1 and back without losing fields", func() {
+		nm := getFullNM()
+		hub := &nodemaintenancev1.NodeMaintenance{}
+		Expect(nm.ConvertTo(hub)).To(Succeed())
+
+		Expect(hub.Spec.Drain).NotTo(BeNil())
+		Expect(hub.Spec.Drain.Timeout).To(Equal(nm.Spec.DrainTimeout))
+		Expect(hub.Spec.Drain.FailurePolicy).To(Equal(nodemaintenancev1.DrainFailurePolicyRollback))
+		Expect(hub.Spec.Drain.PDBPolicy.Type).To(Equal(nodemaintenancev1.PDBPolicyBypassAfter))
+		Expect(hub.Status.Pods.Pending).To(Equal([]nodemaintenancev1.PodReference{{Name: "pod-a"}}))
+		Expect(hub.Status.Pods.ForceDeleted).To(Equal([]nodemaintenancev1.PodReference{{Namespace: "default", Name: "pod-c"}}))
+		Expect(hub.Status.Pods.Evictions[0].Pod).To(Equal(nodemaintenancev1.PodReference{Namespace: "default", Name: "pod-a"}))
+		Expect(hub.Status.Lease.Foreign.HolderIdentity).To(Equal("other"))
+
+		converted := &NodeMaintenance{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.Annotations).ToNot(HaveKey(ConditionsAnnotation))
+		Expect(converted).To(Equal(nm))
+	})
+
+	It("should convert empty NodeMaintenances without drain, pods and lease status", func() {
+		nm := getTestNMO("node01")
+		hub := &nodemaintenancev1.NodeMaintenance{}
+		Expect(nm.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Spec.Drain).To(BeNil())
+		Expect(hub.Status.Pods).To(BeNil())
+		Expect(hub.Status.Lease).To(BeNil())
+		Expect(hub.Status.Conditions).To(BeEmpty())
+
+		converted := &NodeMaintenance{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted).To(Equal(nm))
+	})
+
+	It("should derive the conditions from the status", func() {
+		nm := getFullNM()
+		hub := &nodemaintenancev1.NodeMaintenance{}
+		Expect(nm.ConvertTo(hub)).To(Succeed())
+		Expect(meta.IsStatusConditionFalse(hub.Status.Conditions, nodemaintenancev1.ConditionDrained)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(hub.Status.Conditions, nodemaintenancev1.ConditionFailed)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(hub.Status.Conditions, nodemaintenancev1.ConditionNodeUnreachable)).To(BeTrue())
+
+		nm.Status.Phase = MaintenanceFailed
+		nm.Status.FailureReason = FailureReasonDrainTimeout
+		nm.Status.PhaseTransitions = append(nm.Status.PhaseTransitions, PhaseTransition{Phase: MaintenanceFailed, Time: later})
+		Expect(nm.ConvertTo(hub)).To(Succeed())
+		failed := meta.FindStatusCondition(hub.Status.Conditions, nodemaintenancev1.ConditionFailed)
+		Expect(failed.Status).To(Equal(metav1.ConditionTrue))
+		Expect(failed.Reason).To(Equal(string(FailureReasonDrainTimeout)))
+		Expect(failed.LastTransitionTime).To(Equal(later))
+
+		nm.Status.Phase = MaintenanceSucceeded
+		Expect(nm.ConvertTo(hub)).To(Succeed())
+		drained := meta.FindStatusCondition(hub.Status.Conditions, nodemaintenancev1.ConditionDrained)
+		Expect(drained.Status).To(Equal(metav1.ConditionTrue))
+		Expect(drained.Reason).To(Equal(ConditionReasonDrainCompleted))
+	})
+
+	It("should keep the conditions on a round trip through v1beta1", func() {
+		earlier := metav1.NewTime(now.Add(-time.Hour))
+		hub := &nodemaintenancev1.NodeMaintenance{}
+		Expect(getFullNM().ConvertTo(hub)).To(Succeed())
+		for i := range hub.Status.Conditions {
+			hub.Status.Conditions[i].LastTransitionTime = earlier
+		}
+		meta.SetStatusCondition(&hub.Status.Conditions, metav1.Condition{
+			Type:               "Example",
+			Status:             metav1.ConditionTrue,
+			Reason:             "Test",
+			LastTransitionTime: now,
+		})
+
+		converted := &NodeMaintenance{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted.Annotations).To(HaveKey(ConditionsAnnotation))
+		Expect(hub.Annotations).ToNot(HaveKey(ConditionsAnnotation))
+
+		roundTripped := &nodemaintenancev1.NodeMaintenance{}
+		Expect(converted.ConvertTo(roundTripped)).To(Succeed())
+		Expect(roundTripped.Status.Conditions).To(Equal(hub.Status.Conditions))
+		Expect(roundTripped.Annotations).ToNot(HaveKey(ConditionsAnnotation))
+	})
+
+	It("should keep the transition time of the NodeUnreachable condition while its status doesn't change", func() {
+		earlier := metav1.NewTime(now.Add(-time.Hour))
+		hub := &nodemaintenancev1.NodeMaintenance{}
+		Expect(getFullNM().ConvertTo(hub)).To(Succeed())
+		unreachable := meta.FindStatusCondition(hub.Status.Conditions, nodemaintenancev1.ConditionNodeUnreachable)
+		Expect(unreachable.LastTransitionTime).To(Equal(now))
+		unreachable.LastTransitionTime = earlier
+
+		nm := &NodeMaintenance{}
+		Expect(nm.ConvertFrom(hub)).To(Succeed())
+		reconciled := metav1.NewTime(later.Add(time.Minute))
+		nm.Status.LastReconcileTime = &reconciled
+		Expect(nm.ConvertTo(hub)).To(Succeed())
+		unreachable = meta.FindStatusCondition(hub.Status.Conditions, nodemaintenancev1.ConditionNodeUnreachable)
+		Expect(unreachable.Status).To(Equal(metav1.ConditionTrue))
+		Expect(unreachable.LastTransitionTime).To(Equal(earlier))
+
+		nm.Status.NodeUnreachable = false
+		Expect(nm.ConvertTo(hub)).To(Succeed())
+		unreachable = meta.FindStatusCondition(hub.Status.Conditions, nodemaintenancev1.ConditionNodeUnreachable)
+		Expect(unreachable.Status).To(Equal(metav1.ConditionFalse))
+		Expect(unreachable.LastTransitionTime.Time).To(BeTemporally(">", earlier.Time))
+		Expect(unreachable.LastTransitionTime).ToNot(Equal(reconciled))
+	})
+
+	Context("with the API server", func() {
+
+		var node *corev1.Node
+
+		BeforeEach(func() {
+			node = getTestNode("node-conversion", false)
+			Expect(k8sClient.Create(context.Background(), node)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(context.Background(), node)).To(Succeed())
+		})
+
+		It("should serve NodeMaintenances created as v1beta1 as v1", func() {
+			nm := getTestNMO(node.Name)
+			nm.Spec.DrainTimeout = &metav1.Duration{Duration: time.Hour}
+			Expect(k8sClient.Create(context.Background(), nm)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(context.Background(), nm)).To(Succeed())
+			}()
+
+			hub := &nodemaintenancev1.NodeMaintenance{}
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nm), hub)).To(Succeed())
+			Expect(hub.Spec.NodeName).To(Equal(node.Name))
+			Expect(hub.Spec.Drain).NotTo(BeNil())
+			Expect(hub.Spec.Drain.Timeout.Duration).To(Equal(time.Hour))
+		})
+	})
+})
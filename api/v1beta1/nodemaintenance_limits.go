@@ -0,0 +1,170 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ErrorTopologyLimit          = "can not put node %s into maintenance, %d nodes with label %s=%s are in maintenance already, at most %d are allowed"
+	ErrorClusterPercentageLimit = "can not put node %s into maintenance, %d of %d nodes are in maintenance already, at most %d%% (%d nodes) are allowed"
+)
+
+// TopologyLimit limits the number of nodes in maintenance which have the same value of a topology label
+type TopologyLimit struct {
+	// LabelKey is the key of the topology label, e.g. topology.kubernetes.io/zone. Nodes without the label aren't limited.
+	LabelKey string
+	// MaxNodes is the maximum number of nodes in maintenance with the same value of the label
+	MaxNodes int
+}
+
+// ParseTopologyLimits parses comma separated topology limits in the format <label key>=<max nodes>,
+// e.g. "topology.kubernetes.io/zone=1,example.com/rack=2"
+func ParseTopologyLimits(limits string) ([]TopologyLimit, error) {
+	var parsed []TopologyLimit
+	for _, limit := range strings.Split(limits, ",") {
+		limit = strings.TrimSpace(limit)
+		if limit == "" {
+			continue
+		}
+		i := strings.LastIndex(limit, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid topology limit %q, must be <label key>=<max nodes>", limit)
+		}
+		maxNodes, err := strconv.Atoi(limit[i+1:])
+		if err != nil || maxNodes < 1 {
+			return nil, fmt.Errorf("invalid topology limit %q, max nodes must be a positive number", limit)
+		}
+		parsed = append(parsed, TopologyLimit{LabelKey: limit[:i], MaxNodes: maxNodes})
+	}
+	return parsed, nil
+}
+
+// validateMaintenanceLimits validates that putting the node into maintenance doesn't exceed the configured topology limits
+// and the configured percentage of nodes of the cluster in maintenance
+func (v *NodeMaintenanceValidator) validateMaintenanceLimits(nm *NodeMaintenance) error {
+	if len(webhookConfig.TopologyLimits) == 0 && webhookConfig.MaxNodesInMaintenancePercentage <= 0 {
+		return nil
+	}
+
+	nodesInMaintenance, err := v.getOtherNodesInMaintenance(nm)
+	if err != nil {
+		return fmt.Errorf("could not list NodeMaintenances for validating maintenance limits, please try again: %v", err)
+	}
+	if nodesInMaintenance.Len() == 0 {
+		// the first node in maintenance is always allowed
+		return nil
+	}
+
+	node, err := getNode(nm.Spec.NodeName, v.client)
+	if err != nil {
+		return fmt.Errorf("could not get node for validating maintenance limits, please try again: %v", err)
+	} else if node == nil {
+		return fmt.Errorf(ErrorNodeNotExists, nm.Spec.NodeName)
+	}
+
+	for _, limit := range webhookConfig.TopologyLimits {
+		if err := v.validateTopologyLimit(node, limit, nodesInMaintenance); err != nil {
+			return err
+		}
+	}
+	return v.validateClusterPercentageLimit(node, nodesInMaintenance)
+}
+
+// getOtherNodesInMaintenance returns the names of the nodes which are held in maintenance by other NodeMaintenances.
+// The NodeMaintenances are read uncached, so that maintenances created at the same time are counted.
+func (v *NodeMaintenanceValidator) getOtherNodesInMaintenance(nm *NodeMaintenance) (sets.String, error) {
+	var nodeMaintenances NodeMaintenanceList
+	if err := v.apiReader.List(context.TODO(), &nodeMaintenances); err != nil {
+		return nil, err
+	}
+	nodeNames := sets.NewString()
+	for i := range nodeMaintenances.Items {
+		other := &nodeMaintenances.Items[i]
+		if other.Name != nm.Name && other.Spec.NodeName != nm.Spec.NodeName && holdsNode(other) {
+			nodeNames.Insert(other.Spec.NodeName)
+		}
+	}
+	return nodeNames, nil
+}
+
+// holdsNode returns true if the given NodeMaintenance keeps its node in maintenance.
+// Maintenances which are inactive, deleted, rolled back after the drain timeout,
+// or waiting for a foreign lease without having cordoned the node don't.
+func holdsNode(nm *NodeMaintenance) bool {
+	if !nm.Spec.IsActive() || !nm.DeletionTimestamp.IsZero() || nm.Status.RolledBack {
+		return false
+	}
+	return nm.Status.Phase != MaintenanceWaitingForLease || nm.Status.NodeCordoned
+}
+
+func (v *NodeMaintenanceValidator) validateTopologyLimit(node *v1.Node, limit TopologyLimit, nodesInMaintenance sets.String) error {
+	value, ok := node.Labels[limit.LabelKey]
+	if !ok {
+		return nil
+	}
+
+	var nodes v1.NodeList
+	if err := v.client.List(context.TODO(), &nodes, client.MatchingLabels{limit.LabelKey: value}); err != nil {
+		return fmt.Errorf("could not list nodes for validating the topology limit of %s, please try again: %v", limit.LabelKey, err)
+	}
+	count := 0
+	for _, other := range nodes.Items {
+		if nodesInMaintenance.Has(other.Name) {
+			count++
+		}
+	}
+	if count >= limit.MaxNodes {
+		return fmt.Errorf(ErrorTopologyLimit, node.Name, count, limit.LabelKey, value, limit.MaxNodes)
+	}
+	return nil
+}
+
+func (v *NodeMaintenanceValidator) validateClusterPercentageLimit(node *v1.Node, nodesInMaintenance sets.String) error {
+	percentage := webhookConfig.MaxNodesInMaintenancePercentage
+	if percentage <= 0 {
+		return nil
+	}
+
+	var nodes v1.NodeList
+	if err := v.client.List(context.TODO(), &nodes); err != nil {
+		return fmt.Errorf("could not list nodes for validating the cluster percentage limit, please try again: %v", err)
+	}
+	// round down like maxUnavailable percentages, but always allow one node
+	maxNodes := len(nodes.Items) * percentage / 100
+	if maxNodes < 1 {
+		maxNodes = 1
+	}
+	count := 0
+	for _, other := range nodes.Items {
+		if nodesInMaintenance.Has(other.Name) {
+			count++
+		}
+	}
+	if count >= maxNodes {
+		return fmt.Errorf(ErrorClusterPercentageLimit, node.Name, count, len(nodes.Items), percentage, maxNodes)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package v1beta1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("NodeMaintenance Limits Validation", func() {
+
+	const zoneLabel = "topology.kubernetes.io/zone"
+
+	var limitsValidator *NodeMaintenanceValidator
+
+	newZoneNode := func(name, zone string) *v1.Node {
+		node := getTestNode(name, false)
+		node.Labels = map[string]string{zoneLabel: zone}
+		return node
+	}
+
+	newNM := func(nodeName string, active bool) *NodeMaintenance {
+		nm := getTestNMO(nodeName)
+		if !active {
+			nm.Spec.State = MaintenanceInactive
+		}
+		return nm
+	}
+
+	setup := func(config WebhookConfig, objs ...client.Object) {
+		limitsValidator = newFakeValidator(objs...)
+		// multiple maintenances per node are allowed, so that only the limits are validated
+		config.AllowMultipleMaintenancesPerNode = true
+		SetWebhookConfig(config)
+	}
+
+	AfterEach(func() {
+		SetWebhookConfig(WebhookConfig{})
+	})
+
+	It("should parse topology limits", func() {
+		limits, err := ParseTopologyLimits("topology.kubernetes.io/zone=1, example.com/rack=2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(limits).To(Equal([]TopologyLimit{{LabelKey: zoneLabel, MaxNodes: 1}, {LabelKey: "example.com/rack", MaxNodes: 2}}))
+
+		Expect(ParseTopologyLimits("")).To(BeEmpty())
+		for _, invalid := range []string{"zone", "=1", "zone=0", "zone=one"} {
+			_, err := ParseTopologyLimits(invalid)
+			Expect(err).To(HaveOccurred(), invalid)
+		}
+	})
+
+	Context("with a topology limit", func() {
+
+		var objs []client.Object
+
+		BeforeEach(func() {
+			objs = []client.Object{
+				newZoneNode("node-a1", "a"), newZoneNode("node-a2", "a"), newZoneNode("node-a3", "a"),
+				newZoneNode("node-b1", "b"), getTestNode("node-no-zone", false),
+			}
+		})
+
+		It("should reject nodes exceeding the limit of their zone", func() {
+			setup(WebhookConfig{TopologyLimits: []TopologyLimit{{LabelKey: zoneLabel, MaxNodes: 1}}},
+				append(objs, newNM("node-a1", true))...)
+			_, err := limitsValidator.ValidateCreate(getTestNMO("node-a2"))
+			Expect(err).To(MatchError(ContainSubstring("1 nodes with label %s=a are in maintenance already, at most 1 are allowed", zoneLabel)))
+		})
+
+		It("should allow nodes within the limit of their zone", func() {
+			setup(WebhookConfig{TopologyLimits: []TopologyLimit{{LabelKey: zoneLabel, MaxNodes: 2}}},
+				append(objs, newNM("node-a1", true))...)
+			Expect(limitsValidator.ValidateCreate(getTestNMO("node-a2"))).To(BeEmpty())
+		})
+
+		It("should allow nodes of other zones and nodes without zone", func() {
+			setup(WebhookConfig{TopologyLimits: []TopologyLimit{{LabelKey: zoneLabel, MaxNodes: 1}}},
+				append(objs, newNM("node-a1", true))...)
+			Expect(limitsValidator.ValidateCreate(getTestNMO("node-b1"))).To(BeEmpty())
+			Expect(limitsValidator.ValidateCreate(getTestNMO("node-no-zone"))).To(BeEmpty())
+		})
+
+		It("should not count inactive maintenances and maintenances of the same node", func() {
+			other := newNM("node-a1", true)
+			other.Name = "test-other"
+			setup(WebhookConfig{TopologyLimits: []TopologyLimit{{LabelKey: zoneLabel, MaxNodes: 1}}},
+				append(objs, newNM("node-a3", false), other)...)
+			Expect(limitsValidator.ValidateCreate(getTestNMO("node-a1"))).To(BeEmpty())
+		})
+
+		It("should not count maintenances which don't hold their node", func() {
+			rolledBack := newNM("node-a1", true)
+			rolledBack.Name = "test-rolled-back"
+			rolledBack.Status.RolledBack = true
+			waiting := newNM("node-a2", true)
+			waiting.Name = "test-waiting"
+			waiting.Status.Phase = MaintenanceWaitingForLease
+			deleted := newNM("node-a3", true)
+			deleted.Name = "test-deleted"
+			deleted.Finalizers = []string{NodeMaintenanceFinalizer}
+			deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			setup(WebhookConfig{TopologyLimits: []TopologyLimit{{LabelKey: zoneLabel, MaxNodes: 1}}},
+				append(objs, rolledBack, waiting, deleted)...)
+			Expect(limitsValidator.ValidateCreate(getTestNMO("node-a1"))).To(BeEmpty())
+		})
+
+		It("should count maintenances waiting for a lease which cordoned their node", func() {
+			waiting := newNM("node-a2", true)
+			waiting.Status.Phase = MaintenanceWaitingForLease
+			waiting.Status.NodeCordoned = true
+			setup(WebhookConfig{TopologyLimits: []TopologyLimit{{LabelKey: zoneLabel, MaxNodes: 1}}},
+				append(objs, waiting)...)
+			_, err := limitsValidator.ValidateCreate(getTestNMO("node-a1"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should validate activations", func() {
+			setup(WebhookConfig{TopologyLimits: []TopologyLimit{{LabelKey: zoneLabel, MaxNodes: 1}}},
+				append(objs, newNM("node-a1", true))...)
+			old := newNM("node-a2", false)
+			nm := newNM("node-a2", true)
+			_, err := limitsValidator.ValidateUpdate(nm, old, authenticationv1.UserInfo{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with a cluster percentage limit", func() {
+
+		var objs []client.Object
+
+		BeforeEach(func() {
+			objs = nil
+			for _, name := range []string{"node-1", "node-2", "node-3", "node-4", "node-5"} {
+				objs = append(objs, getTestNode(name, false))
+			}
+		})
+
+		It("should reject nodes exceeding the percentage", func() {
+			setup(WebhookConfig{MaxNodesInMaintenancePercentage: 40}, append(objs, newNM("node-1", true), newNM("node-2", true))...)
+			_, err := limitsValidator.ValidateCreate(getTestNMO("node-3"))
+			Expect(err).To(MatchError(ContainSubstring("2 of 5 nodes are in maintenance already, at most 40% (2 nodes) are allowed")))
+		})
+
+		It("should allow nodes within the percentage", func() {
+			setup(WebhookConfig{MaxNodesInMaintenancePercentage: 40}, append(objs, newNM("node-1", true))...)
+			Expect(limitsValidator.ValidateCreate(getTestNMO("node-2"))).To(BeEmpty())
+		})
+
+		It("should always allow one node", func() {
+			setup(WebhookConfig{MaxNodesInMaintenancePercentage: 10}, objs...)
+			Expect(limitsValidator.ValidateCreate(getTestNMO("node-1"))).To(BeEmpty())
+
+			setup(WebhookConfig{MaxNodesInMaintenancePercentage: 10}, append(objs, newNM("node-1", true))...)
+			_, err := limitsValidator.ValidateCreate(getTestNMO("node-2"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,153 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// RequesterGroupsAnnotation holds the comma separated groups of the user who requested the maintenance
+	RequesterGroupsAnnotation = "nodemaintenance.kubevirt.io/requester-groups"
+
+	MutatingWebhookPath = "/mutate-nodemaintenance-kubevirt-io-v1beta1-nodemaintenance"
+)
+
+// WebhookConfig is the operator configuration used by the NodeMaintenance webhooks
+// +k8s:deepcopy-gen=false
+type WebhookConfig struct {
+	// DefaultReason is set as reason on NodeMaintenances which are created without a reason
+	DefaultReason string
+	// DefaultDrainTimeout is set as drain timeout on NodeMaintenances which are created without one. Zero sets none.
+	DefaultDrainTimeout time.Duration
+	// DefaultFailurePolicy is set as failure policy on NodeMaintenances which are created without one.
+	// Empty sets none, which behaves like KeepCordoned.
+	DefaultFailurePolicy DrainFailurePolicy
+	// DefaultPDBBypassAfter sets a BypassAfter PDB policy with the given duration on NodeMaintenances which are
+	// created without PDB policy. Zero sets none, so that PodDisruptionBudgets are respected.
+	DefaultPDBBypassAfter time.Duration
+	// AllowMultipleMaintenancesPerNode allows several active NodeMaintenances for the same node.
+	// The node stays in maintenance until the last of them is deleted or set inactive.
+	AllowMultipleMaintenancesPerNode bool
+	// LeaseNamespace is the namespace of the node leases. Node leases aren't validated if it is empty.
+	LeaseNamespace string
+	// LeaseHolderIdentity is the holder identity of the node leases of the operator
+	LeaseHolderIdentity string
+	// OperatorUsername is the username of the service account of the operator, which is the only user allowed to
+	// change the requester annotations of NodeMaintenances. Nobody is allowed if it is empty.
+	OperatorUsername string
+	// LastNodeInZoneCheck is the action for maintenances of the last schedulable node of a zone.
+	// Checks with an empty action are ignored, like this one and the following ones.
+	LastNodeInZoneCheck AdmissionCheckAction
+	// SingletonPodsCheck is the action for maintenances of nodes hosting the only replica of a ReplicaSet or StatefulSet
+	SingletonPodsCheck AdmissionCheckAction
+	// LocalVolumesCheck is the action for maintenances of nodes hosting pods with local persistent volumes
+	LocalVolumesCheck AdmissionCheckAction
+	// TopologyLimits limit the number of nodes in maintenance per value of topology labels, e.g. per zone
+	TopologyLimits []TopologyLimit
+	// MaxNodesInMaintenancePercentage limits the percentage of nodes of the cluster in maintenance. Zero disables the limit.
+	MaxNodesInMaintenancePercentage int
+}
+
+var webhookConfig = WebhookConfig{}
+
+// SetWebhookConfig sets the operator configuration used by the NodeMaintenance webhooks
+func SetWebhookConfig(config WebhookConfig) {
+	webhookConfig = config
+}
+
+// ParseDrainFailurePolicy parses the given failure policy case insensitively, empty stays empty
+func ParseDrainFailurePolicy(policy string) (DrainFailurePolicy, error) {
+	for _, p := range []DrainFailurePolicy{"", DrainFailurePolicyKeepCordoned, DrainFailurePolicyRollback} {
+		if strings.EqualFold(policy, string(p)) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("invalid drain failure policy %q, must be one of %s or %s",
+		policy, DrainFailurePolicyKeepCordoned, DrainFailurePolicyRollback)
+}
+
+//+kubebuilder:webhook:path=/mutate-nodemaintenance-kubevirt-io-v1beta1-nodemaintenance,mutating=true,failurePolicy=fail,sideEffects=None,groups=nodemaintenance.kubevirt.io,resources=nodemaintenances,verbs=create,versions=v1beta1,name=mnodemaintenance.kb.io,admissionReviewVersions={v1,v1beta1}
+
+// NodeMaintenanceMutator stamps the requester into NodeMaintenances and defaults omitted spec fields.
+// Needed because the requester is only known from the admission request.
+// +k8s:deepcopy-gen=false
+type NodeMaintenanceMutator struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &NodeMaintenanceMutator{}
+
+// Handle implements admission.Handler
+func (m *NodeMaintenanceMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Create {
+		return m.handleCreate(req)
+	}
+	return admission.Allowed("")
+}
+
+func (m *NodeMaintenanceMutator) handleCreate(req admission.Request) admission.Response {
+	nm := &NodeMaintenance{}
+	if err := m.decoder.Decode(req, nm); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	nodemaintenancelog.Info("mutate create", "name", nm.Name, "requester", req.UserInfo.Username)
+
+	m.Default(nm, req.UserInfo)
+
+	marshaled, err := json.Marshal(nm)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// Default stamps the requester into the annotations of the given NodeMaintenance, and defaults omitted spec fields
+func (m *NodeMaintenanceMutator) Default(nm *NodeMaintenance, user authenticationv1.UserInfo) {
+	if nm.Annotations == nil {
+		nm.Annotations = map[string]string{}
+	}
+	// always overwrite, the requester must not be set by the requester
+	nm.Annotations[RequesterAnnotation] = user.Username
+	nm.Annotations[RequesterGroupsAnnotation] = strings.Join(user.Groups, ",")
+
+	if nm.Spec.Reason == "" {
+		nm.Spec.Reason = webhookConfig.DefaultReason
+	}
+	if nm.Spec.DrainTimeout == nil && webhookConfig.DefaultDrainTimeout > 0 {
+		nm.Spec.DrainTimeout = &metav1.Duration{Duration: webhookConfig.DefaultDrainTimeout}
+	}
+	if nm.Spec.FailurePolicy == "" {
+		nm.Spec.FailurePolicy = webhookConfig.DefaultFailurePolicy
+	}
+	if nm.Spec.PDBPolicy == nil && webhookConfig.DefaultPDBBypassAfter > 0 {
+		nm.Spec.PDBPolicy = &PDBPolicy{
+			Type:        PDBPolicyBypassAfter,
+			BypassAfter: &metav1.Duration{Duration: webhookConfig.DefaultPDBBypassAfter},
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("NodeMaintenance Mutation", func() {
+
+	const nodeName = "node-mutate"
+
+	var mutator *NodeMaintenanceMutator
+	var user authenticationv1.UserInfo
+
+	BeforeEach(func() {
+		testScheme := runtime.NewScheme()
+		Expect(AddToScheme(testScheme)).To(Succeed())
+		decoder, err := admission.NewDecoder(testScheme)
+		Expect(err).ToNot(HaveOccurred())
+
+		mutator = &NodeMaintenanceMutator{decoder: decoder}
+		user = authenticationv1.UserInfo{
+			Username: "admin",
+			Groups:   []string{"system:masters", "system:authenticated"},
+		}
+	})
+
+	AfterEach(func() {
+		SetWebhookConfig(WebhookConfig{})
+	})
+
+	getRequest := func(nm *NodeMaintenance) admission.Request {
+		raw, err := json.Marshal(nm)
+		Expect(err).ToNot(HaveOccurred())
+		return admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Name:      nm.Name,
+				UserInfo:  user,
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		}
+	}
+
+	Describe("creating NodeMaintenance", func() {
+
+		It("should stamp the requester", func() {
+			nm := getTestNMO(nodeName)
+			nm.Annotations = map[string]string{RequesterAnnotation: "somebody-else"}
+			mutator.Default(nm, user)
+			Expect(nm.Annotations).To(HaveKeyWithValue(RequesterAnnotation, "admin"))
+			Expect(nm.Annotations).To(HaveKeyWithValue(RequesterGroupsAnnotation, "system:masters,system:authenticated"))
+		})
+
+		It("should default the reason from the operator configuration", func() {
+			SetWebhookConfig(WebhookConfig{DefaultReason: "planned maintenance"})
+			nm := getTestNMO(nodeName)
+			mutator.Default(nm, user)
+			Expect(nm.Spec.Reason).To(Equal("planned maintenance"))
+
+			nm = getTestNMO(nodeName)
+			nm.Spec.Reason = "kernel upgrade"
+			mutator.Default(nm, user)
+			Expect(nm.Spec.Reason).To(Equal("kernel upgrade"))
+		})
+
+		It("should default the drain timeout from the operator configuration", func() {
+			nm := getTestNMO(nodeName)
+			mutator.Default(nm, user)
+			Expect(nm.Spec.DrainTimeout).To(BeNil())
+
+			SetWebhookConfig(WebhookConfig{DefaultDrainTimeout: time.Hour})
+			mutator.Default(nm, user)
+			Expect(nm.Spec.DrainTimeout).To(Equal(&metav1.Duration{Duration: time.Hour}))
+
+			nm = getTestNMO(nodeName)
+			nm.Spec.DrainTimeout = &metav1.Duration{Duration: time.Minute}
+			mutator.Default(nm, user)
+			Expect(nm.Spec.DrainTimeout).To(Equal(&metav1.Duration{Duration: time.Minute}))
+		})
+
+		It("should default the failure policy from the operator configuration", func() {
+			nm := getTestNMO(nodeName)
+			mutator.Default(nm, user)
+			Expect(nm.Spec.FailurePolicy).To(BeEmpty())
+
+			SetWebhookConfig(WebhookConfig{DefaultFailurePolicy: DrainFailurePolicyRollback})
+			mutator.Default(nm, user)
+			Expect(nm.Spec.FailurePolicy).To(Equal(DrainFailurePolicyRollback))
+
+			nm = getTestNMO(nodeName)
+			nm.Spec.FailurePolicy = DrainFailurePolicyKeepCordoned
+			mutator.Default(nm, user)
+			Expect(nm.Spec.FailurePolicy).To(Equal(DrainFailurePolicyKeepCordoned))
+		})
+
+		It("should default the PDB policy from the operator configuration", func() {
+			nm := getTestNMO(nodeName)
+			mutator.Default(nm, user)
+			Expect(nm.Spec.PDBPolicy).To(BeNil())
+
+			SetWebhookConfig(WebhookConfig{DefaultPDBBypassAfter: 30 * time.Minute})
+			mutator.Default(nm, user)
+			Expect(nm.Spec.PDBPolicy).To(Equal(&PDBPolicy{Type: PDBPolicyBypassAfter, BypassAfter: &metav1.Duration{Duration: 30 * time.Minute}}))
+
+			nm = getTestNMO(nodeName)
+			nm.Spec.PDBPolicy = &PDBPolicy{Type: PDBPolicyRespect}
+			mutator.Default(nm, user)
+			Expect(nm.Spec.PDBPolicy).To(Equal(&PDBPolicy{Type: PDBPolicyRespect}))
+		})
+
+		It("should parse failure policies", func() {
+			Expect(ParseDrainFailurePolicy("rollback")).To(Equal(DrainFailurePolicyRollback))
+			Expect(ParseDrainFailurePolicy("KeepCordoned")).To(Equal(DrainFailurePolicyKeepCordoned))
+			Expect(ParseDrainFailurePolicy("")).To(BeEmpty())
+			_, err := ParseDrainFailurePolicy("uncordon")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should return a patch", func() {
+			resp := mutator.Handle(context.Background(), getRequest(getTestNMO(nodeName)))
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patches).ToNot(BeEmpty())
+		})
+	})
+})
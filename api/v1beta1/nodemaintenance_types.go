@@ -26,6 +26,8 @@ import (
 const (
 	// NodeMaintenanceFinalizer is a finalizer for a NodeMaintenance CR deletion
 	NodeMaintenanceFinalizer string = "foregroundDeleteNodeMaintenance"
+	// NodeNameField is the name of the field index of NodeMaintenances and pods by the name of their node
+	NodeNameField = "spec.nodeName"
 )
 
 // MaintenancePhase contains the phase of maintenance
@@ -38,8 +40,124 @@ const (
 	MaintenanceSucceeded MaintenancePhase = "Succeeded"
 	// MaintenanceFailed - maintenance has failed
 	MaintenanceFailed MaintenancePhase = "Failed"
+	// MaintenanceEnded - maintenance has been ended by setting its state to Inactive, the node is uncordoned
+	MaintenanceEnded MaintenancePhase = "Ended"
+	// MaintenanceWaitingForLease - maintenance waits for the expiry of a lease of the node held by another component
+	MaintenanceWaitingForLease MaintenancePhase = "WaitingForLease"
 )
 
+// MaintenanceState contains the desired state of maintenance
+// +kubebuilder:validation:Enum=Active;Inactive
+type MaintenanceState string
+
+const (
+	// MaintenanceActive - the node should be in maintenance
+	MaintenanceActive MaintenanceState = "Active"
+	// MaintenanceInactive - the node should not be in maintenance
+	MaintenanceInactive MaintenanceState = "Inactive"
+)
+
+// PDBPolicyType defines how PodDisruptionBudgets are handled by the drain
+// +kubebuilder:validation:Enum=Respect;BypassAfter
+type PDBPolicyType string
+
+const (
+	// PDBPolicyRespect - pods are only evicted, the drain waits for as long as PodDisruptionBudgets block the eviction
+	PDBPolicyRespect PDBPolicyType = "Respect"
+	// PDBPolicyBypassAfter - after the bypass deadline the remaining pods are deleted instead of evicted, ignoring PodDisruptionBudgets
+	PDBPolicyBypassAfter PDBPolicyType = "BypassAfter"
+)
+
+// PDBPolicy defines how PodDisruptionBudgets are handled by the drain
+type PDBPolicy struct {
+	// Type is the type of the policy (Respect,BypassAfter)
+	// +kubebuilder:default=Respect
+	Type PDBPolicyType `json:"type"`
+	// BypassAfter is the duration after the start of the maintenance, after which PodDisruptionBudgets are bypassed.
+	// Required for the BypassAfter policy.
+	// +optional
+	BypassAfter *metav1.Duration `json:"bypassAfter,omitempty"`
+}
+
+// DrainFailurePolicy defines what happens with the node when the drain didn't complete within the drain timeout
+// +kubebuilder:validation:Enum=KeepCordoned;Rollback
+type DrainFailurePolicy string
+
+const (
+	// DrainFailurePolicyKeepCordoned - the node stays cordoned and tainted, and its lease is kept
+	DrainFailurePolicyKeepCordoned DrainFailurePolicy = "KeepCordoned"
+	// DrainFailurePolicyRollback - the node is uncordoned and untainted, and its lease is released
+	DrainFailurePolicyRollback DrainFailurePolicy = "Rollback"
+)
+
+// FailureReason is the reason of a failed maintenance
+type FailureReason string
+
+const (
+	// FailureReasonDrainTimeout - the drain didn't complete within the drain timeout
+	FailureReasonDrainTimeout FailureReason = "DrainTimeout"
+	// FailureReasonLeaseRenewal - the lease of the node couldn't be renewed
+	FailureReasonLeaseRenewal FailureReason = "LeaseRenewalFailed"
+)
+
+// PodEvictionReason is the reason why a pod wasn't evicted yet
+type PodEvictionReason string
+
+const (
+	// PodEvictionPDBBlocked - the eviction would violate a PodDisruptionBudget of the pod
+	PodEvictionPDBBlocked PodEvictionReason = "PDBBlocked"
+	// PodEvictionTooManyRequests - the eviction was rejected with 429 Too Many Requests for another reason than a PodDisruptionBudget
+	PodEvictionTooManyRequests PodEvictionReason = "TooManyRequests"
+	// PodEvictionServerError - the eviction failed with 500 Internal Server Error, e.g. because the pod has multiple PodDisruptionBudgets
+	PodEvictionServerError PodEvictionReason = "ServerError"
+	// PodEvictionTerminating - the pod was evicted, but didn't terminate yet
+	PodEvictionTerminating PodEvictionReason = "Terminating"
+	// PodEvictionPending - the eviction is allowed, but wasn't done yet
+	PodEvictionPending PodEvictionReason = "Pending"
+	// PodEvictionFailed - the eviction failed with another error
+	PodEvictionFailed PodEvictionReason = "Failed"
+)
+
+// PodEvictionStatus is the eviction status of a pod of the node which wasn't evicted yet
+type PodEvictionStatus struct {
+	// Namespace is the namespace of the pod
+	Namespace string `json:"namespace"`
+	// Name is the name of the pod
+	Name string `json:"name"`
+	// OwnerKind is the kind of the controller of the pod, if any
+	OwnerKind string `json:"ownerKind,omitempty"`
+	// OwnerName is the name of the controller of the pod, if any
+	OwnerName string `json:"ownerName,omitempty"`
+	// Attempts is the number of drain attempts which didn't evict the pod
+	Attempts int `json:"attempts"`
+	// Reason is the reason why the pod wasn't evicted yet (PDBBlocked,TooManyRequests,ServerError,Terminating,Pending,Failed)
+	Reason PodEvictionReason `json:"reason"`
+	// LastError is the error of the latest eviction attempt, if any
+	LastError string `json:"lastError,omitempty"`
+	// FirstAttemptTime is the time of the first drain attempt which didn't evict the pod
+	FirstAttemptTime metav1.Time `json:"firstAttemptTime"`
+	// LastAttemptTime is the time of the latest drain attempt which didn't evict the pod
+	LastAttemptTime metav1.Time `json:"lastAttemptTime"`
+}
+
+// PhaseTransition records the time the maintenance entered a phase
+type PhaseTransition struct {
+	// Phase is the phase the maintenance entered
+	Phase MaintenancePhase `json:"phase"`
+	// Time is the time the maintenance entered the phase
+	Time metav1.Time `json:"time"`
+}
+
+// ForeignLease describes a lease of the node which is held by another component
+type ForeignLease struct {
+	// HolderIdentity is the identity of the holder of the lease
+	HolderIdentity string `json:"holderIdentity"`
+	// AcquireTime is the time the lease was acquired by its holder
+	AcquireTime *metav1.Time `json:"acquireTime,omitempty"`
+	// ExpiryTime is the time the lease expires, unless it is renewed by its holder
+	ExpiryTime metav1.Time `json:"expiryTime"`
+}
+
 // NodeMaintenanceSpec defines the desired state of NodeMaintenance
 type NodeMaintenanceSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -49,6 +167,42 @@ type NodeMaintenanceSpec struct {
 	NodeName string `json:"nodeName"`
 	// Reason for maintanance
 	Reason string `json:"reason,omitempty"`
+	// State is the desired state of the maintenance (Active,Inactive).
+	// Setting it to Inactive ends the maintenance without deleting the NodeMaintenance, setting it back to Active restarts it.
+	// +kubebuilder:default=Active
+	// +optional
+	State MaintenanceState `json:"state,omitempty"`
+	// ForceDeleteTerminatingPodsAfter enables force deleting the pods of an unreachable node, which are terminating for longer than the given duration.
+	// Pods of unreachable nodes can't terminate, because their kubelet can't confirm that their containers were stopped.
+	// Only use it when the containers of the node are known to be stopped, e.g. because the node is powered off,
+	// since their replacements might run concurrently otherwise.
+	// +optional
+	ForceDeleteTerminatingPodsAfter *metav1.Duration `json:"forceDeleteTerminatingPodsAfter,omitempty"`
+	// PDBPolicy defines how PodDisruptionBudgets are handled by the drain, they are respected by default.
+	// Bypassing them is meant for maintenances which must proceed in any case, e.g. because of failed hardware.
+	// +optional
+	PDBPolicy *PDBPolicy `json:"pdbPolicy,omitempty"`
+	// DrainTimeout is the maximum duration of the drain since the start of the maintenance.
+	// The maintenance fails when the drain didn't complete in time. Without it the drain is retried forever.
+	// +optional
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+	// FailurePolicy defines what happens with the node when the drain didn't complete within the drain timeout (KeepCordoned,Rollback).
+	// Defaults to the failure policy configured in the operator, or KeepCordoned.
+	// +optional
+	FailurePolicy DrainFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// IsActive returns true if the node should be in maintenance
+func (s *NodeMaintenanceSpec) IsActive() bool {
+	return s.State != MaintenanceInactive
+}
+
+// ConfigMapReference references a ConfigMap
+type ConfigMapReference struct {
+	// Namespace is the namespace of the ConfigMap
+	Namespace string `json:"namespace"`
+	// Name is the name of the ConfigMap
+	Name string `json:"name"`
 }
 
 // NodeMaintenanceStatus defines the observed state of NodeMaintenance
@@ -56,20 +210,74 @@ type NodeMaintenanceStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Phase is the represtation of the maintenance progress (Running,Succeeded,Failed)
+	// Phase is the represtation of the maintenance progress (Running,Succeeded,Failed,Ended,WaitingForLease)
 	Phase MaintenancePhase `json:"phase,omitempty"`
 	// LastError represents the latest error if any in the latest reconciliation
 	LastError string `json:"lastError,omitempty"`
-	// PendingPods is a list of pending pods for eviction
+	// FailureReason is the reason of the Failed phase (DrainTimeout,LeaseRenewalFailed)
+	FailureReason FailureReason `json:"failureReason,omitempty"`
+	// RolledBack is true when the node was taken out of maintenance because of the Rollback failure policy
+	RolledBack bool `json:"rolledBack,omitempty"`
+	// NodeCordoned is true while the node is cordoned and tainted by the maintenance.
+	// It stays true when the maintenance waits for the lease of the node after it was taken over by another component.
+	NodeCordoned bool `json:"nodeCordoned,omitempty"`
+	// PendingPods is a list of pending pods for eviction, identified by namespace/name,
+	// capped at a maximum number of pods, see PendingPodsCount
 	PendingPods []string `json:"pendingPods,omitempty"`
+	// EvictedPods is a list of pods which were evicted so far, identified by namespace/name,
+	// capped at a maximum number of pods, see EvictedPodsCount
+	EvictedPods []string `json:"evictedPods,omitempty"`
+	// PendingPodsCount is the number of pending pods for eviction
+	PendingPodsCount int `json:"pendingPodsCount,omitempty"`
+	// EvictedPodsCount is the number of pods which were evicted so far
+	EvictedPodsCount int `json:"evictedPodsCount,omitempty"`
+	// SkippedPodsCount is the number of pods on the node which are not evicted, e.g. DaemonSet pods
+	SkippedPodsCount int `json:"skippedPodsCount,omitempty"`
+	// FailedPodsCount is the number of pending pods whose latest eviction attempt failed, among the pods of PodEvictions
+	FailedPodsCount int `json:"failedPodsCount,omitempty"`
+	// PodListConfigMap references the ConfigMap with the complete lists of pending and evicted pods, if enabled in the operator
+	PodListConfigMap *ConfigMapReference `json:"podListConfigMap,omitempty"`
+	// PodEvictions is the eviction status of the pods which weren't evicted by the latest drain attempt,
+	// capped at a maximum number of pods
+	PodEvictions []PodEvictionStatus `json:"podEvictions,omitempty"`
 	// TotalPods is the total number of all pods on the node from the start
 	TotalPods int `json:"totalpods,omitempty"`
 	// EvictionPods is the total number of pods up for eviction from the start
 	EvictionPods int `json:"evictionPods,omitempty"`
 	// Consecutive number of errors upon obtaining a lease
 	ErrorOnLeaseCount int `json:"errorOnLeaseCount,omitempty"`
+	// StartTime is the time the maintenance was started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// DrainStartTime is the time the drain of the node started, after its lease was obtained.
+	// The drain timeout is measured from this time.
+	DrainStartTime *metav1.Time `json:"drainStartTime,omitempty"`
+	// DrainCompletedTime is the time all pods were evicted for the first time
+	DrainCompletedTime *metav1.Time `json:"drainCompletedTime,omitempty"`
+	// EndTime is the time the maintenance was ended
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// LastReconcileTime is the time of the latest reconciliation
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+	// LeaseExpiryTime is the time the lease of the node expires, unless it is renewed.
+	// The lease is renewed before it expires, as long as the maintenance is active.
+	LeaseExpiryTime *metav1.Time `json:"leaseExpiryTime,omitempty"`
+	// ForeignLease describes the lease of the node held by another component, which the maintenance is waiting for
+	ForeignLease *ForeignLease `json:"foreignLease,omitempty"`
+	// NodeUnreachable is true when the Ready condition of the node is Unknown.
+	// Pods of unreachable nodes which are terminating for a while are not waited for.
+	NodeUnreachable bool `json:"nodeUnreachable,omitempty"`
+	// ForceDeletedPods is a list of pods of the unreachable node which were force deleted, see spec.forceDeleteTerminatingPodsAfter
+	ForceDeletedPods []string `json:"forceDeletedPods,omitempty"`
+	// PDBBypassTime is the time the pods of the node started to be deleted without eviction,
+	// after the bypass deadline of the PDB policy
+	PDBBypassTime *metav1.Time `json:"pdbBypassTime,omitempty"`
+	// ViolatedPDBs is a list of PodDisruptionBudgets which were violated by deleting pods after the bypass deadline of the PDB policy
+	ViolatedPDBs []string `json:"violatedPDBs,omitempty"`
+	// PhaseTransitions is the timeline of the latest phase changes, oldest first
+	PhaseTransitions []PhaseTransition `json:"phaseTransitions,omitempty"`
 }
 
+//+genclient
+//+genclient:nonNamespaced
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Cluster
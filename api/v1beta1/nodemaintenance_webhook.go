@@ -19,18 +19,27 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"kubevirt.io/node-maintenance-operator/pkg/lease"
+	"kubevirt.io/node-maintenance-operator/pkg/pdb"
 )
 
 const (
@@ -38,6 +47,10 @@ const (
 	ErrorNodeMaintenanceExists   = "invalid nodeName, a NodeMaintenance for node %s already exists"
 	ErrorNodeNameUpdateForbidden = "updating spec.NodeName isn't allowed"
 	ErrorMasterQuorumViolation   = "can not put master node into maintenance at this moment, it would violate the master quorum"
+	ErrorNodeLeaseHeld           = "can not put node %s into maintenance at this moment, its lease is held by %s until %s"
+	ErrorPDBPolicyBypassAfter    = "spec.pdbPolicy.bypassAfter must be set to a positive duration for the BypassAfter policy"
+	ErrorDrainTimeout            = "spec.drainTimeout must be a positive duration"
+	ErrorRequesterAnnotation     = "updating annotation %s isn't allowed"
 )
 
 const (
@@ -46,6 +59,13 @@ const (
 	LabelNameRoleMaster    = "node-role.kubernetes.io/master"
 )
 
+const (
+	ValidatingWebhookPath = "/validate-nodemaintenance-kubevirt-io-v1beta1-nodemaintenance"
+
+	// EventReasonDeletionRequested is the reason of the event emitted when deletion of a maintenance was requested
+	EventReasonDeletionRequested = "DeletionRequested"
+)
+
 const (
 	WebhookCertDir  = "/apiserver.local.config/certificates"
 	WebhookCertName = "apiserver.crt"
@@ -57,18 +77,33 @@ var nodemaintenancelog = logf.Log.WithName("nodemaintenance-resource")
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 
-// NodeMaintenanceValidator validates NodeMaintenance resources. Needed because we need a client for validation
+// NodeMaintenanceValidator validates NodeMaintenance resources. Needed because we need a client for validation,
+// and because admission warnings can only be returned by an admission.Handler.
 // +k8s:deepcopy-gen=false
 type NodeMaintenanceValidator struct {
 	client client.Client
+	// apiReader reads NodeMaintenances from the API server, in order to count maintenances which were just created,
+	// but aren't in the cache of client yet
+	apiReader client.Reader
+	decoder   *admission.Decoder
+	// recorder records who requested the deletion of NodeMaintenances
+	recorder record.EventRecorder
+	// policyV1 is true if the cluster serves policy/v1 PodDisruptionBudgets, otherwise policy/v1beta1 is used
+	policyV1 bool
 }
 
 var validator *NodeMaintenanceValidator
 
+var _ admission.Handler = &NodeMaintenanceValidator{}
+
 func (r *NodeMaintenance) SetupWebhookWithManager(mgr ctrl.Manager) error {
-	// init the validator!
-	validator = &NodeMaintenanceValidator{
-		client: mgr.GetClient(),
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	policyV1, err := pdb.IsPolicyV1Supported(discoveryClient)
+	if err != nil {
+		return fmt.Errorf("could not check for policy/v1 support: %v", err)
 	}
 
 	// check if OLM injected certs
@@ -89,73 +124,234 @@ func (r *NodeMaintenance) SetupWebhookWithManager(mgr ctrl.Manager) error {
 		nodemaintenancelog.Info("OLM injected certs for webhooks not found")
 	}
 
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+
+	// init the validator!
+	validator = &NodeMaintenanceValidator{
+		client:    mgr.GetClient(),
+		apiReader: mgr.GetAPIReader(),
+		decoder:   decoder,
+		recorder:  mgr.GetEventRecorderFor("nodemaintenance-webhook"),
+		policyV1:  policyV1,
+	}
+	mgr.GetWebhookServer().Register(ValidatingWebhookPath, &webhook.Admission{Handler: validator})
+	mgr.GetWebhookServer().Register(QuorumGuardValidatingWebhookPath, &webhook.Admission{
+		Handler: &QuorumGuardValidator{
+			client:   mgr.GetClient(),
+			decoder:  decoder,
+			policyV1: policyV1,
+		},
+	})
+	mgr.GetWebhookServer().Register(MutatingWebhookPath, &webhook.Admission{
+		Handler: &NodeMaintenanceMutator{
+			decoder: decoder,
+		},
+	})
+
+	// NodeMaintenance implements neither webhook.Defaulter nor webhook.Validator,
+	// so the builder only registers the conversion webhook
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
 }
 
+// AddNodeNameFieldIndex registers the field index of NodeMaintenances by the name of their node, see NodeNameField.
+// The validator relies on it for finding the NodeMaintenances of a node in the cache of the manager.
+func AddNodeNameFieldIndex(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &NodeMaintenance{}, NodeNameField, func(obj client.Object) []string {
+		return []string{obj.(*NodeMaintenance).Spec.NodeName}
+	})
+}
+
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
 //+kubebuilder:webhook:path=/validate-nodemaintenance-kubevirt-io-v1beta1-nodemaintenance,mutating=false,failurePolicy=fail,sideEffects=None,groups=nodemaintenance.kubevirt.io,resources=nodemaintenances,verbs=create;update,versions=v1beta1,name=vnodemaintenance.kb.io,admissionReviewVersions={v1,v1beta1}
+// The deletion webhook only records the requester, and ignores failures, so that NodeMaintenances can be deleted while the operator is down
+//+kubebuilder:webhook:path=/validate-nodemaintenance-kubevirt-io-v1beta1-nodemaintenance,mutating=false,failurePolicy=ignore,sideEffects=NoneOnDryRun,groups=nodemaintenance.kubevirt.io,resources=nodemaintenances,verbs=delete,versions=v1beta1,name=vnodemaintenancedeletion.kb.io,admissionReviewVersions={v1,v1beta1}
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Handle implements admission.Handler, it denies invalid NodeMaintenances and returns the warnings of the admission checks
+func (v *NodeMaintenanceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	nm := &NodeMaintenance{}
+	var warnings []string
+	var err error
+	switch req.Operation {
+	case admissionv1.Create:
+		if err := v.decoder.Decode(req, nm); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		nodemaintenancelog.Info("validate create", "name", nm.Name)
+		warnings, err = v.ValidateCreate(nm)
+	case admissionv1.Update:
+		old := &NodeMaintenance{}
+		if err := v.decoder.Decode(req, nm); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		nodemaintenancelog.Info("validate update", "name", nm.Name)
+		warnings, err = v.ValidateUpdate(nm, old, req.UserInfo)
+	case admissionv1.Delete:
+		return v.handleDelete(ctx, req)
+	default:
+		return admission.Allowed("")
+	}
 
-var _ webhook.Validator = &NodeMaintenance{}
-
-// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *NodeMaintenance) ValidateCreate() error {
-	nodemaintenancelog.Info("validate create", "name", r.Name)
-
-	if validator == nil {
-		return fmt.Errorf("nodemaintenance validator isn't initialized yet")
+	if err != nil {
+		return admission.Denied(err.Error()).WithWarnings(warnings...)
 	}
-	return validator.ValidateCreate(r)
+	return admission.Allowed("").WithWarnings(warnings...)
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *NodeMaintenance) ValidateUpdate(old runtime.Object) error {
-	nodemaintenancelog.Info("validate update", "name", r.Name)
+// handleDelete records who requested the deletion of a NodeMaintenance with an event, deletions are always allowed
+func (v *NodeMaintenanceValidator) handleDelete(ctx context.Context, req admission.Request) admission.Response {
+	if req.DryRun != nil && *req.DryRun {
+		return admission.Allowed("")
+	}
 
-	if validator == nil {
-		return fmt.Errorf("nodemaintenance validator isn't initialized yet")
+	nm := &NodeMaintenance{}
+	if len(req.OldObject.Raw) > 0 {
+		if err := v.decoder.DecodeRaw(req.OldObject, nm); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	} else if err := v.client.Get(ctx, types.NamespacedName{Name: req.Name}, nm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return admission.Allowed("")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
 	}
-	return validator.ValidateUpdate(r, old.(*NodeMaintenance))
-}
+	nodemaintenancelog.Info("validate delete", "name", nm.Name, "requester", req.UserInfo.Username)
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *NodeMaintenance) ValidateDelete() error {
-	nodemaintenancelog.Info("validate delete", "name", r.Name)
+	v.recorder.Eventf(nm, v1.EventTypeNormal, EventReasonDeletionRequested,
+		"Deletion of maintenance for node %s requested by %s (groups: %s)", nm.Spec.NodeName, req.UserInfo.Username, strings.Join(req.UserInfo.Groups, ","))
+	return admission.Allowed("")
+}
 
-	if validator == nil {
-		return fmt.Errorf("nodemaintenance validator isn't initialized yet")
+// ValidateCreate validates a new NodeMaintenance, it returns the warnings of the admission checks
+func (v *NodeMaintenanceValidator) ValidateCreate(nm *NodeMaintenance) ([]string, error) {
+	if err := validateDrainSpec(&nm.Spec); err != nil {
+		nodemaintenancelog.Info("validation failed", "error", err)
+		return nil, err
 	}
-	return nil
-}
 
-func (v *NodeMaintenanceValidator) ValidateCreate(nm *NodeMaintenance) error {
 	// Validate that node with given name exists
 	if err := v.validateNodeExists(nm.Spec.NodeName); err != nil {
 		nodemaintenancelog.Info("validation failed", "error", err)
-		return err
+		return nil, err
+	}
+
+	// Inactive NodeMaintenances don't put the node into maintenance
+	if !nm.Spec.IsActive() {
+		return nil, nil
+	}
+
+	return v.validateActivation(nm)
+}
+
+// validateActivation validates that the node of the given NodeMaintenance can be put into maintenance,
+// and returns the warnings of the admission checks
+func (v *NodeMaintenanceValidator) validateActivation(nm *NodeMaintenance) ([]string, error) {
+	// Validate that no other active NodeMaintenance for given node exists yet, unless that's allowed
+	if !webhookConfig.AllowMultipleMaintenancesPerNode {
+		if err := v.validateNoNodeMaintenanceExists(nm.Spec.NodeName, nm.Name); err != nil {
+			nodemaintenancelog.Info("validation failed", "error", err)
+			return nil, err
+		}
 	}
 
-	// Validate that no NodeMaintenance for given node exists yet
-	if err := v.validateNoNodeMaintenanceExists(nm.Spec.NodeName); err != nil {
+	// Validate that the node isn't owned by another component, e.g. a remediation operator
+	if err := v.validateNoForeignLease(nm.Spec.NodeName); err != nil {
 		nodemaintenancelog.Info("validation failed", "error", err)
-		return err
+		return nil, err
 	}
 
 	// Validate that NodeMaintenance for master nodes don't violate quorum
 	if err := v.validateMasterQuorum(nm.Spec.NodeName); err != nil {
 		nodemaintenancelog.Info("validation failed", "error", err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	// Validate that NodeMaintenance doesn't violate the quorum of QuorumGuards
+	warnings, err := v.validateQuorumGuards(nm)
+	if err != nil {
+		nodemaintenancelog.Info("validation failed", "error", err)
+		return warnings, err
+	}
+
+	// Validate that the node doesn't exceed the limits of nodes in maintenance
+	if err := v.validateMaintenanceLimits(nm); err != nil {
+		nodemaintenancelog.Info("validation failed", "error", err)
+		return warnings, err
+	}
+
+	// Run the checks of risky but allowed maintenances, which warn or deny depending on the configuration
+	checkWarnings, err := v.runAdmissionChecks(nm.Spec.NodeName)
+	warnings = append(warnings, checkWarnings...)
+	if err != nil {
+		nodemaintenancelog.Info("validation failed", "error", err)
+	}
+	return warnings, err
 }
 
-func (v *NodeMaintenanceValidator) ValidateUpdate(new, old *NodeMaintenance) error {
+// ValidateUpdate validates a NodeMaintenance updated by the given user, it returns the warnings of the admission checks
+func (v *NodeMaintenanceValidator) ValidateUpdate(new, old *NodeMaintenance, user authenticationv1.UserInfo) ([]string, error) {
 	// Validate that node name didn't change
 	if new.Spec.NodeName != old.Spec.NodeName {
 		nodemaintenancelog.Info("validation failed", "error", ErrorNodeNameUpdateForbidden)
-		return fmt.Errorf(ErrorNodeNameUpdateForbidden)
+		return nil, fmt.Errorf(ErrorNodeNameUpdateForbidden)
+	}
+
+	// Validate that the requesters, which are recorded in the history of the maintenance, aren't forged
+	if err := validateRequesterAnnotations(new, old, user); err != nil {
+		nodemaintenancelog.Info("validation failed", "error", err)
+		return nil, err
+	}
+
+	if err := validateDrainSpec(&new.Spec); err != nil {
+		nodemaintenancelog.Info("validation failed", "error", err)
+		return nil, err
+	}
+
+	// Validate that the node can be put into maintenance again
+	if !old.Spec.IsActive() && new.Spec.IsActive() {
+		return v.validateActivation(new)
+	}
+	return nil, nil
+}
+
+// validateRequesterAnnotations validates that only the operator changes the requester annotations,
+// which are stamped by the mutating webhook
+func validateRequesterAnnotations(new, old *NodeMaintenance, user authenticationv1.UserInfo) error {
+	if webhookConfig.OperatorUsername != "" && user.Username == webhookConfig.OperatorUsername {
+		return nil
+	}
+	for _, annotation := range []string{RequesterAnnotation, RequesterGroupsAnnotation} {
+		newValue, newFound := new.Annotations[annotation]
+		oldValue, oldFound := old.Annotations[annotation]
+		if newFound != oldFound || newValue != oldValue {
+			return fmt.Errorf(ErrorRequesterAnnotation, annotation)
+		}
+	}
+	return nil
+}
+
+// validateDrainSpec validates the spec fields which configure the drain
+func validateDrainSpec(spec *NodeMaintenanceSpec) error {
+	if spec.DrainTimeout != nil && spec.DrainTimeout.Duration <= 0 {
+		return fmt.Errorf(ErrorDrainTimeout)
+	}
+	return validatePDBPolicy(spec.PDBPolicy)
+}
+
+func validatePDBPolicy(policy *PDBPolicy) error {
+	if policy == nil || policy.Type != PDBPolicyBypassAfter {
+		return nil
+	}
+	if policy.BypassAfter == nil || policy.BypassAfter.Duration <= 0 {
+		return fmt.Errorf(ErrorPDBPolicyBypassAfter)
 	}
 	return nil
 }
@@ -169,14 +365,14 @@ func (v *NodeMaintenanceValidator) validateNodeExists(nodeName string) error {
 	return nil
 }
 
-func (v *NodeMaintenanceValidator) validateNoNodeMaintenanceExists(nodeName string) error {
+func (v *NodeMaintenanceValidator) validateNoNodeMaintenanceExists(nodeName string, ownName string) error {
 	var nodeMaintenances NodeMaintenanceList
-	if err := v.client.List(context.TODO(), &nodeMaintenances, &client.ListOptions{}); err != nil {
+	if err := v.client.List(context.TODO(), &nodeMaintenances, client.MatchingFields{NodeNameField: nodeName}); err != nil {
 		return fmt.Errorf("could not list NodeMaintenances for validating spec.NodeName, please try again: %v", err)
 	}
 
 	for _, nm := range nodeMaintenances.Items {
-		if nm.Spec.NodeName == nodeName {
+		if nm.Spec.NodeName == nodeName && nm.Name != ownName && nm.Spec.IsActive() {
 			return fmt.Errorf(ErrorNodeMaintenanceExists, nodeName)
 		}
 	}
@@ -184,6 +380,22 @@ func (v *NodeMaintenanceValidator) validateNoNodeMaintenanceExists(nodeName stri
 	return nil
 }
 
+func (v *NodeMaintenanceValidator) validateNoForeignLease(nodeName string) error {
+	if webhookConfig.LeaseNamespace == "" {
+		return nil
+	}
+
+	leaseManager := lease.NewManager(v.client, webhookConfig.LeaseNamespace, webhookConfig.LeaseHolderIdentity, 0)
+	status, err := leaseManager.Check(context.TODO(), nodeName)
+	if err != nil {
+		return fmt.Errorf("could not get node lease for validating spec.NodeName, please try again: %v", err)
+	}
+	if status != nil && status.Valid && status.HolderIdentity != webhookConfig.LeaseHolderIdentity {
+		return fmt.Errorf(ErrorNodeLeaseHeld, nodeName, status.HolderIdentity, status.DueTime.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
 func (v *NodeMaintenanceValidator) validateMasterQuorum(nodeName string) error {
 	// check if the node is a master node
 	if node, err := getNode(nodeName, v.client); err != nil {
@@ -197,12 +409,12 @@ func (v *NodeMaintenanceValidator) validateMasterQuorum(nodeName string) error {
 	}
 
 	// check the etcd-quorum-guard PodDisruptionBudget if we can drain a master node
-	var pdb v1beta1.PodDisruptionBudget
 	key := types.NamespacedName{
 		Namespace: EtcdQuorumPDBNamespace,
 		Name:      EtcdQuorumPDBName,
 	}
-	if err := v.client.Get(context.TODO(), key, &pdb); err != nil {
+	quorumPDB, err := pdb.Get(context.TODO(), v.client, v.policyV1, key)
+	if err != nil {
 		if apierrors.IsNotFound(err) {
 			// TODO do we need a fallback for k8s clusters?
 			nodemaintenancelog.Info("etcd-quorum-guard PDB not found. Skipping master quorum validation.")
@@ -210,7 +422,7 @@ func (v *NodeMaintenanceValidator) validateMasterQuorum(nodeName string) error {
 		}
 		return fmt.Errorf("could not get etcd-quorum-guard PDB for master quorum validation, please try again: %v", err)
 	}
-	if pdb.Status.DisruptionsAllowed == 0 {
+	if quorumPDB.Status.DisruptionsAllowed == 0 {
 		return fmt.Errorf(ErrorMasterQuorumViolation)
 	}
 	return nil
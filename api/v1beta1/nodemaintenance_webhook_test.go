@@ -2,15 +2,26 @@ package v1beta1
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	coordv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 var _ = Describe("NodeMaintenance Validation", func() {
@@ -37,7 +48,7 @@ var _ = Describe("NodeMaintenance Validation", func() {
 
 			It("should be rejected", func() {
 				nm := getTestNMO(nonExistingNodeName)
-				err := nm.ValidateCreate()
+				err := validateCreate(nm)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring(ErrorNodeNotExists, nonExistingNodeName))
 			})
@@ -71,13 +82,58 @@ var _ = Describe("NodeMaintenance Validation", func() {
 			It("should be rejected", func() {
 				nm := getTestNMO(existingNodeName)
 				Eventually(func() error {
-					return nm.ValidateCreate()
+					return validateCreate(nm)
 				}, time.Second, 200*time.Millisecond).Should(And(
 					HaveOccurred(),
 					WithTransform(func(err error) string { return err.Error() }, ContainSubstring(ErrorNodeMaintenanceExists, existingNodeName)),
 				))
 			})
 
+			It("should not be rejected when multiple maintenances per node are allowed", func() {
+				SetWebhookConfig(WebhookConfig{AllowMultipleMaintenancesPerNode: true})
+				defer SetWebhookConfig(WebhookConfig{})
+
+				nm := getTestNMO(existingNodeName)
+				nm.Name = "test-second"
+				Consistently(func() error {
+					return validateCreate(nm)
+				}, time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+			})
+
+		})
+
+		Context("for node with inactive maintenance", func() {
+
+			var node *v1.Node
+			var nmExisting *NodeMaintenance
+
+			BeforeEach(func() {
+				node = getTestNode(existingNodeName, false)
+				err := k8sClient.Create(context.Background(), node)
+				Expect(err).ToNot(HaveOccurred())
+
+				nmExisting = getTestNMO(existingNodeName)
+				nmExisting.Name = "test-inactive"
+				nmExisting.Spec.State = MaintenanceInactive
+				err = k8sClient.Create(context.Background(), nmExisting)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				err := k8sClient.Delete(context.Background(), node)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = k8sClient.Delete(context.Background(), nmExisting)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should not be rejected", func() {
+				nm := getTestNMO(existingNodeName)
+				Eventually(func() error {
+					return validateCreate(nm)
+				}, time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+			})
+
 		})
 
 		Context("for master node", func() {
@@ -95,64 +151,78 @@ var _ = Describe("NodeMaintenance Validation", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			Context("with potential quorum violation", func() {
+			It("should discover the policy/v1 API", func() {
+				Expect(validator.policyV1).To(BeTrue())
+			})
 
-				var pdb *v1beta1.PodDisruptionBudget
+			for _, policyV1 := range []bool{true, false} {
+				policyV1 := policyV1
 
-				BeforeEach(func() {
-					pdb = getTestPDB()
-					err := k8sClient.Create(context.Background(), pdb)
-					Expect(err).ToNot(HaveOccurred())
-				})
+				Context(fmt.Sprintf("with policy/v1 PDBs %v", policyV1), func() {
 
-				AfterEach(func() {
-					err := k8sClient.Delete(context.Background(), pdb)
-					Expect(err).ToNot(HaveOccurred())
-				})
+					var discoveredPolicyV1 bool
 
-				It("should be rejected", func() {
-					nm := getTestNMO(existingNodeName)
-					err := nm.ValidateCreate()
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(ContainSubstring(ErrorMasterQuorumViolation))
-				})
+					BeforeEach(func() {
+						discoveredPolicyV1 = validator.policyV1
+						validator.policyV1 = policyV1
+					})
 
-			})
+					AfterEach(func() {
+						validator.policyV1 = discoveredPolicyV1
+					})
 
-			Context("without potential quorum violation", func() {
+					Context("with potential quorum violation", func() {
 
-				var pdb *v1beta1.PodDisruptionBudget
+						var pdb client.Object
 
-				BeforeEach(func() {
-					pdb = getTestPDB()
-					err := k8sClient.Create(context.Background(), pdb)
-					Expect(err).ToNot(HaveOccurred())
+						BeforeEach(func() {
+							pdb = createTestPDB(policyV1, 0)
+						})
 
-					pdb.Status.DisruptionsAllowed = 1
-					err = k8sClient.Status().Update(context.Background(), pdb)
-					Expect(err).ToNot(HaveOccurred())
-				})
+						AfterEach(func() {
+							err := k8sClient.Delete(context.Background(), pdb)
+							Expect(err).ToNot(HaveOccurred())
+						})
 
-				AfterEach(func() {
-					err := k8sClient.Delete(context.Background(), pdb)
-					Expect(err).ToNot(HaveOccurred())
-				})
+						It("should be rejected", func() {
+							nm := getTestNMO(existingNodeName)
+							err := validateCreate(nm)
+							Expect(err).To(HaveOccurred())
+							Expect(err.Error()).To(ContainSubstring(ErrorMasterQuorumViolation))
+						})
 
-				It("should not be rejected", func() {
-					nm := getTestNMO(existingNodeName)
-					Eventually(func() error {
-						return nm.ValidateCreate()
-					}, time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
-				})
+					})
 
-			})
+					Context("without potential quorum violation", func() {
+
+						var pdb client.Object
+
+						BeforeEach(func() {
+							pdb = createTestPDB(policyV1, 1)
+						})
+
+						AfterEach(func() {
+							err := k8sClient.Delete(context.Background(), pdb)
+							Expect(err).ToNot(HaveOccurred())
+						})
+
+						It("should not be rejected", func() {
+							nm := getTestNMO(existingNodeName)
+							Eventually(func() error {
+								return validateCreate(nm)
+							}, time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
+						})
+
+					})
+				})
+			}
 
 			Context("without etcd quorum guard PDB", func() {
 
 				It("should not be rejected", func() {
 					nm := getTestNMO(existingNodeName)
 					Eventually(func() error {
-						return nm.ValidateCreate()
+						return validateCreate(nm)
 					}, time.Second, 200*time.Millisecond).ShouldNot(HaveOccurred())
 				})
 
@@ -163,12 +233,57 @@ var _ = Describe("NodeMaintenance Validation", func() {
 
 	Describe("updating NodeMaintenance", func() {
 
+		Context("from inactive to active state", func() {
+
+			var node *v1.Node
+			var nmExisting *NodeMaintenance
+
+			BeforeEach(func() {
+				node = getTestNode(existingNodeName, false)
+				err := k8sClient.Create(context.Background(), node)
+				Expect(err).ToNot(HaveOccurred())
+
+				nmExisting = getTestNMO(existingNodeName)
+				err = k8sClient.Create(context.Background(), nmExisting)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				err := k8sClient.Delete(context.Background(), node)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = k8sClient.Delete(context.Background(), nmExisting)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should be rejected when another maintenance for the node is active", func() {
+				nmOld := getTestNMO(existingNodeName)
+				nmOld.Name = "test-inactive"
+				nmOld.Spec.State = MaintenanceInactive
+				nm := nmOld.DeepCopy()
+				nm.Spec.State = MaintenanceActive
+				Eventually(func() error {
+					return validateUpdate(nm, nmOld)
+				}, time.Second, 200*time.Millisecond).Should(And(
+					HaveOccurred(),
+					WithTransform(func(err error) string { return err.Error() }, ContainSubstring(ErrorNodeMaintenanceExists, existingNodeName)),
+				))
+			})
+
+			It("should not be rejected when updating the active maintenance itself", func() {
+				nm := nmExisting.DeepCopy()
+				nm.Spec.Reason = "new reason"
+				Expect(validateUpdate(nm, nmExisting)).To(Succeed())
+			})
+
+		})
+
 		Context("with new nodeName", func() {
 
 			It("should be rejected", func() {
 				nmOld := getTestNMO(existingNodeName)
 				nm := getTestNMO("newNodeName")
-				err := nm.ValidateUpdate(nmOld)
+				err := validateUpdate(nm, nmOld)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring(ErrorNodeNameUpdateForbidden))
 			})
@@ -177,6 +292,205 @@ var _ = Describe("NodeMaintenance Validation", func() {
 	})
 })
 
+var _ = Describe("NodeMaintenance Lease Validation", func() {
+
+	const nodeName = "node-leased"
+	const leaseNamespace = "test-lease-ns"
+	const holderIdentity = "node-maintenance"
+
+	var validator *NodeMaintenanceValidator
+	var cl client.Client
+
+	BeforeEach(func() {
+		cl = fake.NewClientBuilder().Build()
+		validator = &NodeMaintenanceValidator{client: cl}
+		SetWebhookConfig(WebhookConfig{
+			LeaseNamespace:      leaseNamespace,
+			LeaseHolderIdentity: holderIdentity,
+		})
+	})
+
+	AfterEach(func() {
+		SetWebhookConfig(WebhookConfig{})
+	})
+
+	createLease := func(holder string, renewTime time.Time) {
+		lease := &coordv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: leaseNamespace,
+				Name:      nodeName,
+			},
+			Spec: coordv1.LeaseSpec{
+				HolderIdentity:       pointer.StringPtr(holder),
+				LeaseDurationSeconds: pointer.Int32Ptr(3600),
+				AcquireTime:          &metav1.MicroTime{Time: renewTime},
+				RenewTime:            &metav1.MicroTime{Time: renewTime},
+			},
+		}
+		Expect(cl.Create(context.TODO(), lease)).To(Succeed())
+	}
+
+	It("should reject nodes with a valid lease of another holder", func() {
+		createLease("remediation", time.Now())
+		err := validator.validateNoForeignLease(nodeName)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("its lease is held by remediation"))
+	})
+
+	It("should not reject nodes with an expired lease of another holder", func() {
+		createLease("remediation", time.Now().Add(-2*time.Hour))
+		Expect(validator.validateNoForeignLease(nodeName)).To(Succeed())
+	})
+
+	It("should not reject nodes with a lease of the operator", func() {
+		createLease(holderIdentity, time.Now())
+		Expect(validator.validateNoForeignLease(nodeName)).To(Succeed())
+	})
+
+	It("should not reject nodes without lease", func() {
+		Expect(validator.validateNoForeignLease(nodeName)).To(Succeed())
+	})
+
+	It("should not check leases without lease namespace", func() {
+		createLease("remediation", time.Now())
+		SetWebhookConfig(WebhookConfig{})
+		Expect(validator.validateNoForeignLease(nodeName)).To(Succeed())
+	})
+})
+
+var _ = Describe("NodeMaintenance Drain Spec Validation", func() {
+
+	It("should reject a non positive drain timeout", func() {
+		spec := &NodeMaintenanceSpec{DrainTimeout: &metav1.Duration{}}
+		Expect(validateDrainSpec(spec)).To(MatchError(ErrorDrainTimeout))
+
+		spec.DrainTimeout.Duration = time.Hour
+		Expect(validateDrainSpec(spec)).To(Succeed())
+	})
+
+	It("should reject the BypassAfter policy without duration", func() {
+		policy := &PDBPolicy{Type: PDBPolicyBypassAfter}
+		Expect(validatePDBPolicy(policy)).To(MatchError(ErrorPDBPolicyBypassAfter))
+
+		policy.BypassAfter = &metav1.Duration{Duration: -time.Minute}
+		Expect(validatePDBPolicy(policy)).To(MatchError(ErrorPDBPolicyBypassAfter))
+	})
+
+	It("should accept valid policies", func() {
+		Expect(validatePDBPolicy(nil)).To(Succeed())
+		Expect(validatePDBPolicy(&PDBPolicy{Type: PDBPolicyRespect})).To(Succeed())
+		Expect(validatePDBPolicy(&PDBPolicy{Type: PDBPolicyBypassAfter, BypassAfter: &metav1.Duration{Duration: time.Hour}})).To(Succeed())
+	})
+})
+
+var _ = Describe("NodeMaintenance Requester Annotations Validation", func() {
+
+	const operatorUsername = "system:serviceaccount:node-maintenance:node-maintenance-operator"
+
+	var old *NodeMaintenance
+
+	BeforeEach(func() {
+		old = getTestNMO("node-requested")
+		old.Annotations = map[string]string{RequesterAnnotation: "admin", RequesterGroupsAnnotation: "system:masters"}
+		SetWebhookConfig(WebhookConfig{OperatorUsername: operatorUsername})
+	})
+
+	AfterEach(func() {
+		SetWebhookConfig(WebhookConfig{})
+	})
+
+	It("should reject changes of the requester annotations by users", func() {
+		user := authenticationv1.UserInfo{Username: "admin"}
+
+		nm := old.DeepCopy()
+		nm.Annotations[RequesterAnnotation] = "somebody-else"
+		Expect(validateRequesterAnnotations(nm, old, user)).To(MatchError(fmt.Sprintf(ErrorRequesterAnnotation, RequesterAnnotation)))
+
+		nm = old.DeepCopy()
+		delete(nm.Annotations, RequesterGroupsAnnotation)
+		Expect(validateRequesterAnnotations(nm, old, user)).To(MatchError(fmt.Sprintf(ErrorRequesterAnnotation, RequesterGroupsAnnotation)))
+
+	})
+
+	It("should allow changes of other annotations by users", func() {
+		nm := old.DeepCopy()
+		nm.Annotations["example.com/ticket"] = "1234"
+		Expect(validateRequesterAnnotations(nm, old, authenticationv1.UserInfo{Username: "admin"})).To(Succeed())
+	})
+
+	It("should allow changes of the requester annotations by the operator", func() {
+		nm := old.DeepCopy()
+		nm.Annotations[RequesterAnnotation] = "somebody-else"
+		Expect(validateRequesterAnnotations(nm, old, authenticationv1.UserInfo{Username: operatorUsername})).To(Succeed())
+	})
+
+	It("should not allow anybody without operator username", func() {
+		SetWebhookConfig(WebhookConfig{})
+		nm := old.DeepCopy()
+		nm.Annotations[RequesterAnnotation] = "somebody-else"
+		Expect(validateRequesterAnnotations(nm, old, authenticationv1.UserInfo{})).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("NodeMaintenance Deletion Validation", func() {
+
+	var validator *NodeMaintenanceValidator
+	var recorder *record.FakeRecorder
+	var nm *NodeMaintenance
+
+	BeforeEach(func() {
+		nm = getTestNMO("node-deleted")
+		validator = newFakeValidator(nm)
+		recorder = record.NewFakeRecorder(10)
+		validator.recorder = recorder
+	})
+
+	getDeleteRequest := func() admission.Request {
+		raw, err := json.Marshal(nm)
+		Expect(err).ToNot(HaveOccurred())
+		return admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Delete,
+				Name:      nm.Name,
+				UserInfo:  authenticationv1.UserInfo{Username: "admin", Groups: []string{"system:masters"}},
+				OldObject: runtime.RawExtension{Raw: raw},
+			},
+		}
+	}
+
+	It("should allow the deletion and record the requester with an event", func() {
+		resp := validator.Handle(context.Background(), getDeleteRequest())
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patches).To(BeEmpty())
+		Expect(recorder.Events).To(Receive(And(ContainSubstring(EventReasonDeletionRequested), ContainSubstring("admin"))))
+
+		// the requester is only recorded in the event, the NodeMaintenance is left untouched
+		stored := &NodeMaintenance{}
+		Expect(validator.client.Get(context.Background(), client.ObjectKeyFromObject(nm), stored)).To(Succeed())
+		Expect(stored.Annotations).To(Equal(nm.Annotations))
+	})
+
+	It("should not record anything on dry run", func() {
+		req := getDeleteRequest()
+		req.DryRun = pointer.Bool(true)
+		resp := validator.Handle(context.Background(), req)
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(recorder.Events).ToNot(Receive())
+	})
+})
+
+// validateCreate validates a new NodeMaintenance with the validator of the webhook, ignoring the warnings
+func validateCreate(nm *NodeMaintenance) error {
+	_, err := validator.ValidateCreate(nm)
+	return err
+}
+
+// validateUpdate validates an updated NodeMaintenance with the validator of the webhook, ignoring the warnings
+func validateUpdate(nm, old *NodeMaintenance) error {
+	_, err := validator.ValidateUpdate(nm, old, authenticationv1.UserInfo{Username: "test-user"})
+	return err
+}
+
 func getTestNMO(nodeName string) *NodeMaintenance {
 	return &NodeMaintenance{
 		ObjectMeta: metav1.ObjectMeta{
@@ -210,3 +524,21 @@ func getTestPDB() *v1beta1.PodDisruptionBudget {
 		},
 	}
 }
+
+// createTestPDB creates the etcd-quorum-guard PDB with the policy/v1 or the policy/v1beta1 API
+func createTestPDB(policyV1 bool, disruptionsAllowed int32) client.Object {
+	var pdb client.Object
+	if policyV1 {
+		pdbV1 := &policyv1.PodDisruptionBudget{ObjectMeta: getTestPDB().ObjectMeta}
+		ExpectWithOffset(1, k8sClient.Create(context.Background(), pdbV1)).To(Succeed())
+		pdbV1.Status.DisruptionsAllowed = disruptionsAllowed
+		pdb = pdbV1
+	} else {
+		pdbV1beta1 := getTestPDB()
+		ExpectWithOffset(1, k8sClient.Create(context.Background(), pdbV1beta1)).To(Succeed())
+		pdbV1beta1.Status.DisruptionsAllowed = disruptionsAllowed
+		pdb = pdbV1beta1
+	}
+	ExpectWithOffset(1, k8sClient.Status().Update(context.Background(), pdb)).To(Succeed())
+	return pdb
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// RequesterAnnotation can be set on a NodeMaintenance for identifying who requested the maintenance
+	RequesterAnnotation = "nodemaintenance.kubevirt.io/requester"
+
+	// RecordNodeNameLabel is the label on a NodeMaintenanceRecord holding the name of the maintained node,
+	// see RecordLabelValue
+	RecordNodeNameLabel = "nodemaintenance.kubevirt.io/node-name"
+	// RecordNodeMaintenanceLabel is the label on a NodeMaintenanceRecord holding the name of the recorded NodeMaintenance,
+	// see RecordLabelValue
+	RecordNodeMaintenanceLabel = "nodemaintenance.kubevirt.io/nodemaintenance"
+
+	// maxLabelValueLength is the max length of a label value
+	maxLabelValueLength = 63
+	// recordLabelHashLength is the length of the hash suffix of shortened label values
+	recordLabelHashLength = 10
+)
+
+// RecordLabelValue returns the value of the RecordNodeNameLabel and RecordNodeMaintenanceLabel labels for the given name.
+// Names are up to 253 characters long, but label values only up to 63. Longer names are shortened to a prefix of the name
+// and a hash of the full name. The full names are kept in the spec and status of the record.
+func RecordLabelValue(name string) string {
+	if len(name) <= maxLabelValueLength {
+		return name
+	}
+	hash := sha256.Sum256([]byte(name))
+	prefix := name[:maxLabelValueLength-recordLabelHashLength-1]
+	return prefix + "-" + hex.EncodeToString(hash[:])[:recordLabelHashLength]
+}
+
+// NodeMaintenanceRecordStatus is the history of an ended NodeMaintenance
+type NodeMaintenanceRecordStatus struct {
+	// NodeMaintenanceName is the name of the recorded NodeMaintenance
+	NodeMaintenanceName string `json:"nodeMaintenanceName"`
+	// NodeMaintenanceUID is the UID of the recorded NodeMaintenance
+	NodeMaintenanceUID types.UID `json:"nodeMaintenanceUID"`
+	// Requester identifies who requested the maintenance, if known
+	Requester string `json:"requester,omitempty"`
+	// RequesterGroups are the groups of the user who requested the maintenance, if known
+	RequesterGroups []string `json:"requesterGroups,omitempty"`
+	// StartTime is the time the maintenance was requested
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// DrainCompletedTime is the time all pods were evicted for the first time
+	DrainCompletedTime *metav1.Time `json:"drainCompletedTime,omitempty"`
+	// EndTime is the time the maintenance was ended
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// PhaseTransitions is the timeline of the latest phase changes, oldest first
+	PhaseTransitions []PhaseTransition `json:"phaseTransitions,omitempty"`
+	// Phase is the last phase of the maintenance
+	Phase MaintenancePhase `json:"phase,omitempty"`
+	// LastError is the last error of the maintenance, if any
+	LastError string `json:"lastError,omitempty"`
+	// TotalPods is the total number of all pods on the node when the maintenance started
+	TotalPods int `json:"totalpods,omitempty"`
+	// EvictionPods is the total number of pods up for eviction when the maintenance started
+	EvictionPods int `json:"evictionPods,omitempty"`
+	// EvictedPods is the list of pods which were evicted during the maintenance
+	EvictedPods []string `json:"evictedPods,omitempty"`
+	// PendingPods is the list of pods which were still pending for eviction when the maintenance ended
+	PendingPods []string `json:"pendingPods,omitempty"`
+}
+
+//+genclient
+//+genclient:nonNamespaced
+//+genclient:noStatus
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Start",type=date,JSONPath=`.status.startTime`
+//+kubebuilder:printcolumn:name="End",type=date,JSONPath=`.status.endTime`
+
+// NodeMaintenanceRecord is the Schema for the nodemaintenancerecords API.
+// It is written when a NodeMaintenance is deleted, and keeps its history after the NodeMaintenance is gone.
+type NodeMaintenanceRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the spec of the recorded NodeMaintenance
+	Spec NodeMaintenanceSpec `json:"spec,omitempty"`
+	// Status is the history of the recorded NodeMaintenance
+	Status NodeMaintenanceRecordStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NodeMaintenanceRecordList contains a list of NodeMaintenanceRecord
+type NodeMaintenanceRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeMaintenanceRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeMaintenanceRecord{}, &NodeMaintenanceRecordList{})
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodDisruptionBudgetReference references a PodDisruptionBudget
+type PodDisruptionBudgetReference struct {
+	// Namespace is the namespace of the PodDisruptionBudget
+	Namespace string `json:"namespace"`
+	// Name is the name of the PodDisruptionBudget
+	Name string `json:"name"`
+}
+
+// QuorumGuardSpec defines the members of a quorum, and how many of them must stay available.
+// Exactly one of MinAvailable and PodDisruptionBudget must be set.
+type QuorumGuardSpec struct {
+	// NodeSelector selects the nodes the guard applies to, e.g. master nodes. An empty selector selects all nodes.
+	// Without PodSelector, the selected nodes are the members of the quorum.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// Namespace is the namespace of the pods selected by PodSelector. An empty namespace selects pods of all namespaces.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// PodSelector selects the pods in Namespace which are the members of the quorum.
+	// The guard only applies to nodes hosting such pods.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// MinAvailable is the minimum number of ready members of the quorum, which must stay available when the node
+	// and all other nodes in maintenance are unavailable
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinAvailable *int32 `json:"minAvailable,omitempty"`
+	// PodDisruptionBudget references a PodDisruptionBudget which guards the quorum, instead of MinAvailable.
+	// Maintenances are denied while it doesn't allow disruptions. Without NodeSelector,
+	// the guard only applies to nodes hosting pods selected by the PodDisruptionBudget.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetReference `json:"podDisruptionBudget,omitempty"`
+}
+
+//+genclient
+//+genclient:nonNamespaced
+//+genclient:noStatus
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Min Available",type=integer,JSONPath=`.spec.minAvailable`
+//+kubebuilder:printcolumn:name="PDB",type=string,JSONPath=`.spec.podDisruptionBudget.name`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// QuorumGuard is the Schema for the quorumguards API.
+// The validating webhook denies NodeMaintenances which would break the quorum of the guarded pods or nodes,
+// e.g. of Ceph monitors, Kafka brokers or ZooKeeper servers.
+type QuorumGuard struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec QuorumGuardSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// QuorumGuardList contains a list of QuorumGuard
+type QuorumGuardList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuorumGuard `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuorumGuard{}, &QuorumGuardList{})
+}
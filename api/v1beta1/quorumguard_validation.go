@@ -0,0 +1,245 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubevirt.io/node-maintenance-operator/pkg/pdb"
+)
+
+const (
+	ErrorQuorumGuardViolation    = "can not put node %s into maintenance at this moment, it would violate quorum guard %s: %s"
+	ErrorQuorumGuardNotEvaluated = "can not put node %s into maintenance at this moment, quorum guard %s can't be evaluated: %v"
+	WarningQuorumGuardInvalid    = "quorum guard %s is invalid, it doesn't select node %s: %v"
+)
+
+//+kubebuilder:rbac:groups=nodemaintenance.kubevirt.io,resources=quorumguards,verbs=get;list;watch
+
+// validateQuorumGuards validates that putting the node into maintenance doesn't break the quorum of any QuorumGuard.
+// It fails closed: QuorumGuards which might select the node, but can't be evaluated, deny the maintenance.
+// Invalid QuorumGuards which don't select the node by their node selector are returned as warnings.
+func (v *NodeMaintenanceValidator) validateQuorumGuards(nm *NodeMaintenance) ([]string, error) {
+	var guards QuorumGuardList
+	if err := v.client.List(context.TODO(), &guards); err != nil {
+		return nil, fmt.Errorf("could not list QuorumGuards for quorum validation, please try again: %v", err)
+	}
+	if len(guards.Items) == 0 {
+		return nil, nil
+	}
+
+	node, err := getNode(nm.Spec.NodeName, v.client)
+	if err != nil {
+		return nil, fmt.Errorf("could not get node for quorum validation, please try again: %v", err)
+	} else if node == nil {
+		return nil, fmt.Errorf(ErrorNodeNotExists, nm.Spec.NodeName)
+	}
+	nodesInMaintenance, err := v.getOtherNodesInMaintenance(nm)
+	if err != nil {
+		return nil, fmt.Errorf("could not list NodeMaintenances for quorum validation, please try again: %v", err)
+	}
+
+	var warnings []string
+	for i := range guards.Items {
+		guard := &guards.Items[i]
+		if err := validateQuorumGuardSpec(&guard.Spec); err != nil {
+			if !mightSelectNode(&guard.Spec, node) {
+				warnings = append(warnings, fmt.Sprintf(WarningQuorumGuardInvalid, guard.Name, node.Name, err))
+				continue
+			}
+			return warnings, fmt.Errorf(ErrorQuorumGuardNotEvaluated, node.Name, guard.Name, err)
+		}
+		violation, err := v.checkQuorumGuard(guard, node, nodesInMaintenance)
+		if err != nil {
+			return warnings, fmt.Errorf(ErrorQuorumGuardNotEvaluated, node.Name, guard.Name, err)
+		}
+		if violation != "" {
+			return warnings, fmt.Errorf(ErrorQuorumGuardViolation, node.Name, guard.Name, violation)
+		}
+	}
+	return warnings, nil
+}
+
+// mightSelectNode returns false if the node selector of the guard is valid and doesn't select the node
+func mightSelectNode(spec *QuorumGuardSpec, node *v1.Node) bool {
+	if spec.NodeSelector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(spec.NodeSelector)
+	return err != nil || selector.Matches(labels.Set(node.Labels))
+}
+
+// checkQuorumGuard returns a description of the violation if putting the node into maintenance breaks the quorum of the guard.
+// The spec of the guard must be valid, see validateQuorumGuardSpec.
+func (v *NodeMaintenanceValidator) checkQuorumGuard(guard *QuorumGuard, node *v1.Node, nodesInMaintenance sets.String) (string, error) {
+	spec := &guard.Spec
+	var nodeSelector labels.Selector
+	if spec.NodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.NodeSelector)
+		if err != nil {
+			return "", fmt.Errorf("invalid spec.nodeSelector: %v", err)
+		}
+		if !selector.Matches(labels.Set(node.Labels)) {
+			return "", nil
+		}
+		nodeSelector = selector
+	}
+
+	if spec.PodDisruptionBudget != nil {
+		return v.checkPDBQuorum(spec.PodDisruptionBudget, node, nodeSelector != nil)
+	}
+	if spec.PodSelector != nil {
+		return v.checkPodQuorum(spec, node, nodesInMaintenance)
+	}
+	if nodeSelector == nil {
+		nodeSelector = labels.Everything()
+	}
+	return v.checkNodeQuorum(*spec.MinAvailable, nodeSelector, node, nodesInMaintenance)
+}
+
+// checkPDBQuorum checks whether the referenced PodDisruptionBudget allows disruptions. Unless the guard selects the node
+// with its node selector already, it only applies to nodes hosting pods selected by the PodDisruptionBudget.
+func (v *NodeMaintenanceValidator) checkPDBQuorum(ref *PodDisruptionBudgetReference, node *v1.Node, nodeSelected bool) (string, error) {
+	guardPDB, err := pdb.Get(context.TODO(), v.client, v.policyV1, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("PodDisruptionBudget %s/%s not found", ref.Namespace, ref.Name)
+		}
+		return "", err
+	}
+
+	if !nodeSelected {
+		if guardPDB.Spec.Selector == nil {
+			return "", nil
+		}
+		podSelector, err := metav1.LabelSelectorAsSelector(guardPDB.Spec.Selector)
+		if err != nil {
+			return "", fmt.Errorf("invalid selector of PodDisruptionBudget %s/%s: %v", ref.Namespace, ref.Name, err)
+		}
+		pods, err := v.listQuorumPods(ref.Namespace, podSelector)
+		if err != nil {
+			return "", err
+		}
+		if countPodsOnNodes(pods, sets.NewString(node.Name)) == 0 {
+			return "", nil
+		}
+	}
+
+	if guardPDB.Status.DisruptionsAllowed <= 0 {
+		return fmt.Sprintf("PodDisruptionBudget %s/%s allows no disruptions", ref.Namespace, ref.Name), nil
+	}
+	return "", nil
+}
+
+// checkPodQuorum checks whether enough ready pods selected by the pod selector of the guard stay available.
+// It only applies to nodes hosting such pods.
+func (v *NodeMaintenanceValidator) checkPodQuorum(spec *QuorumGuardSpec, node *v1.Node, nodesInMaintenance sets.String) (string, error) {
+	podSelector, err := metav1.LabelSelectorAsSelector(spec.PodSelector)
+	if err != nil {
+		return "", fmt.Errorf("invalid spec.podSelector: %v", err)
+	}
+	pods, err := v.listQuorumPods(spec.Namespace, podSelector)
+	if err != nil {
+		return "", err
+	}
+	onNode := countPodsOnNodes(pods, sets.NewString(node.Name))
+	if onNode == 0 {
+		return "", nil
+	}
+
+	unavailableNodes := sets.NewString(node.Name).Union(nodesInMaintenance)
+	available := 0
+	for i := range pods {
+		if isPodReady(&pods[i]) && !unavailableNodes.Has(pods[i].Spec.NodeName) {
+			available++
+		}
+	}
+	if available < int(*spec.MinAvailable) {
+		return fmt.Sprintf("%d ready pods would be available, at least %d are required", available, *spec.MinAvailable), nil
+	}
+	return "", nil
+}
+
+// checkNodeQuorum checks whether enough ready nodes selected by the node selector stay available
+func (v *NodeMaintenanceValidator) checkNodeQuorum(minAvailable int32, nodeSelector labels.Selector, node *v1.Node, nodesInMaintenance sets.String) (string, error) {
+	var nodes v1.NodeList
+	if err := v.client.List(context.TODO(), &nodes, client.MatchingLabelsSelector{Selector: nodeSelector}); err != nil {
+		return "", err
+	}
+	available := 0
+	for i := range nodes.Items {
+		other := &nodes.Items[i]
+		if other.Name != node.Name && !nodesInMaintenance.Has(other.Name) && isNodeReady(other) {
+			available++
+		}
+	}
+	if available < int(minAvailable) {
+		return fmt.Sprintf("%d ready nodes would be available, at least %d are required", available, minAvailable), nil
+	}
+	return "", nil
+}
+
+// listQuorumPods lists the pods of the namespace which are selected by the selector and aren't terminated
+func (v *NodeMaintenanceValidator) listQuorumPods(namespace string, selector labels.Selector) ([]v1.Pod, error) {
+	var podList v1.PodList
+	if err := v.client.List(context.TODO(), &podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	var pods []v1.Pod
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func countPodsOnNodes(pods []v1.Pod, nodeNames sets.String) int {
+	count := 0
+	for i := range pods {
+		if nodeNames.Has(pods[i].Spec.NodeName) {
+			count++
+		}
+	}
+	return count
+}
+
+func isPodReady(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func isNodeReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
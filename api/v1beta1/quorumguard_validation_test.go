@@ -0,0 +1,214 @@
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("QuorumGuard Validation", func() {
+
+	var guardValidator *NodeMaintenanceValidator
+
+	newReadyNode := func(name string, isMaster bool) *v1.Node {
+		node := getTestNode(name, isMaster)
+		node.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+		return node
+	}
+
+	newQuorumPod := func(name, nodeName, app string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "quorum", Name: name, Labels: map[string]string{"app": app}},
+			Spec:       v1.PodSpec{NodeName: nodeName},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			},
+		}
+	}
+
+	newGuard := func(name string, spec QuorumGuardSpec) *QuorumGuard {
+		return &QuorumGuard{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+
+	setup := func(objs ...client.Object) {
+		guardValidator = newFakeValidator(objs...)
+	}
+
+	Context("with a pod selector", func() {
+
+		var objs []client.Object
+
+		BeforeEach(func() {
+			objs = []client.Object{
+				newReadyNode("node-1", false), newReadyNode("node-2", false), newReadyNode("node-3", false), newReadyNode("node-4", false),
+				newQuorumPod("zk-0", "node-1", "zookeeper"), newQuorumPod("zk-1", "node-2", "zookeeper"), newQuorumPod("zk-2", "node-3", "zookeeper"),
+				newGuard("zookeeper", QuorumGuardSpec{
+					Namespace:    "quorum",
+					PodSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "zookeeper"}},
+					MinAvailable: pointer.Int32Ptr(2),
+				}),
+			}
+		})
+
+		It("should allow the maintenance of one member", func() {
+			setup(objs...)
+			Expect(guardValidator.ValidateCreate(getTestNMO("node-1"))).To(BeEmpty())
+		})
+
+		It("should reject the maintenance of a member while another member is in maintenance", func() {
+			setup(append(objs, getTestNMO("node-2"))...)
+			_, err := guardValidator.ValidateCreate(getTestNMO("node-1"))
+			Expect(err).To(MatchError(ContainSubstring("it would violate quorum guard zookeeper: 1 ready pods would be available, at least 2 are required")))
+		})
+
+		It("should allow the maintenance of nodes without members", func() {
+			setup(append(objs, getTestNMO("node-2"))...)
+			Expect(guardValidator.ValidateCreate(getTestNMO("node-4"))).To(BeEmpty())
+		})
+	})
+
+	Context("with a node selector", func() {
+
+		var objs []client.Object
+
+		BeforeEach(func() {
+			objs = []client.Object{
+				newReadyNode("master-1", true), newReadyNode("master-2", true), newReadyNode("master-3", true), newReadyNode("worker-1", false),
+				newGuard("masters", QuorumGuardSpec{
+					NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      LabelNameRoleMaster,
+						Operator: metav1.LabelSelectorOpExists,
+					}}},
+					MinAvailable: pointer.Int32Ptr(2),
+				}),
+			}
+		})
+
+		It("should reject the maintenance of a selected node while another one is in maintenance", func() {
+			setup(objs...)
+			Expect(guardValidator.ValidateCreate(getTestNMO("master-1"))).To(BeEmpty())
+
+			setup(append(objs, getTestNMO("master-2"))...)
+			_, err := guardValidator.ValidateCreate(getTestNMO("master-1"))
+			Expect(err).To(MatchError(ContainSubstring("1 ready nodes would be available, at least 2 are required")))
+		})
+
+		It("should allow the maintenance of other nodes", func() {
+			setup(append(objs, getTestNMO("master-2"))...)
+			Expect(guardValidator.ValidateCreate(getTestNMO("worker-1"))).To(BeEmpty())
+		})
+	})
+
+	Context("with a PodDisruptionBudget", func() {
+
+		var objs []client.Object
+
+		BeforeEach(func() {
+			guardPDB := &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "quorum", Name: "ceph-mon"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ceph-mon"}}},
+				Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+			}
+			objs = []client.Object{
+				newReadyNode("node-1", false), newReadyNode("node-2", false),
+				newQuorumPod("mon-a", "node-1", "ceph-mon"), guardPDB,
+				newGuard("ceph", QuorumGuardSpec{PodDisruptionBudget: &PodDisruptionBudgetReference{Namespace: "quorum", Name: "ceph-mon"}}),
+			}
+		})
+
+		It("should reject the maintenance of nodes hosting pods of the PodDisruptionBudget without allowed disruptions", func() {
+			setup(objs...)
+			_, err := guardValidator.ValidateCreate(getTestNMO("node-1"))
+			Expect(err).To(MatchError(ContainSubstring("PodDisruptionBudget quorum/ceph-mon allows no disruptions")))
+		})
+
+		It("should allow the maintenance of other nodes", func() {
+			setup(objs...)
+			Expect(guardValidator.ValidateCreate(getTestNMO("node-2"))).To(BeEmpty())
+		})
+	})
+
+	Context("which can't be evaluated", func() {
+
+		It("should reject the maintenance of nodes selected by invalid guards", func() {
+			setup(newReadyNode("node-1", false), newGuard("invalid", QuorumGuardSpec{}))
+			_, err := guardValidator.ValidateCreate(getTestNMO("node-1"))
+			Expect(err).To(MatchError(ContainSubstring("quorum guard invalid can't be evaluated: exactly one of spec.minAvailable and spec.podDisruptionBudget must be set")))
+		})
+
+		It("should reject the maintenance of nodes selected by guards with a missing PodDisruptionBudget", func() {
+			setup(newReadyNode("node-1", false),
+				newGuard("missing", QuorumGuardSpec{PodDisruptionBudget: &PodDisruptionBudgetReference{Namespace: "quorum", Name: "missing"}}))
+			_, err := guardValidator.ValidateCreate(getTestNMO("node-1"))
+			Expect(err).To(MatchError(ContainSubstring("quorum guard missing can't be evaluated: PodDisruptionBudget quorum/missing not found")))
+		})
+
+		It("should allow the maintenance of nodes not selected by invalid guards with a warning", func() {
+			setup(newReadyNode("node-1", false), newGuard("invalid", QuorumGuardSpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{LabelNameRoleMaster: ""}},
+			}))
+			warnings, err := guardValidator.ValidateCreate(getTestNMO("node-1"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("quorum guard invalid is invalid, it doesn't select node node-1")))
+		})
+	})
+})
+
+var _ = Describe("QuorumGuard Webhook", func() {
+
+	var guardValidator *QuorumGuardValidator
+
+	BeforeEach(func() {
+		validator := newFakeValidator()
+		guardValidator = &QuorumGuardValidator{client: validator.client, decoder: validator.decoder, policyV1: true}
+	})
+
+	handle := func(spec QuorumGuardSpec) admission.Response {
+		raw, err := json.Marshal(&QuorumGuard{ObjectMeta: metav1.ObjectMeta{Name: "guard"}, Spec: spec})
+		Expect(err).NotTo(HaveOccurred())
+		return guardValidator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+	}
+
+	It("should reject invalid guards", func() {
+		for _, spec := range []QuorumGuardSpec{
+			{},
+			{MinAvailable: pointer.Int32Ptr(1), PodDisruptionBudget: &PodDisruptionBudgetReference{Namespace: "quorum", Name: "pdb"}},
+			{MinAvailable: pointer.Int32Ptr(0)},
+			{PodDisruptionBudget: &PodDisruptionBudgetReference{Name: "pdb"}},
+			{MinAvailable: pointer.Int32Ptr(1), NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "role", Operator: "Invalid"}}}},
+		} {
+			Expect(handle(spec).Allowed).To(BeFalse(), "%+v", spec)
+		}
+	})
+
+	It("should allow valid guards", func() {
+		response := handle(QuorumGuardSpec{
+			Namespace:    "quorum",
+			PodSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "zookeeper"}},
+			MinAvailable: pointer.Int32Ptr(2),
+		})
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Warnings).To(BeEmpty())
+	})
+
+	It("should warn about a missing PodDisruptionBudget", func() {
+		response := handle(QuorumGuardSpec{PodDisruptionBudget: &PodDisruptionBudgetReference{Namespace: "quorum", Name: "ceph-mon"}})
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Warnings).To(ConsistOf(ContainSubstring("PodDisruptionBudget quorum/ceph-mon not found")))
+	})
+})
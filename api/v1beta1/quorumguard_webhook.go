@@ -0,0 +1,108 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"kubevirt.io/node-maintenance-operator/pkg/pdb"
+)
+
+const (
+	QuorumGuardValidatingWebhookPath = "/validate-nodemaintenance-kubevirt-io-v1beta1-quorumguard"
+
+	WarningQuorumGuardPDBNotFound = "PodDisruptionBudget %s/%s not found, maintenances of the nodes selected by the quorum guard are denied until it exists"
+)
+
+//+kubebuilder:webhook:path=/validate-nodemaintenance-kubevirt-io-v1beta1-quorumguard,mutating=false,failurePolicy=fail,sideEffects=None,groups=nodemaintenance.kubevirt.io,resources=quorumguards,verbs=create;update,versions=v1beta1,name=vquorumguard.kb.io,admissionReviewVersions={v1,v1beta1}
+
+// QuorumGuardValidator denies invalid QuorumGuards, which would deny all maintenances of the nodes they select
+// +k8s:deepcopy-gen=false
+type QuorumGuardValidator struct {
+	client  client.Client
+	decoder *admission.Decoder
+	// policyV1 is true if the cluster serves policy/v1 PodDisruptionBudgets, otherwise policy/v1beta1 is used
+	policyV1 bool
+}
+
+var _ admission.Handler = &QuorumGuardValidator{}
+
+// Handle implements admission.Handler, it denies invalid QuorumGuards and warns about missing PodDisruptionBudgets
+func (v *QuorumGuardValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+	guard := &QuorumGuard{}
+	if err := v.decoder.Decode(req, guard); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	nodemaintenancelog.Info("validate quorum guard", "name", guard.Name)
+
+	if err := validateQuorumGuardSpec(&guard.Spec); err != nil {
+		nodemaintenancelog.Info("validation failed", "error", err)
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("").WithWarnings(v.getQuorumGuardWarnings(ctx, guard)...)
+}
+
+// getQuorumGuardWarnings warns about a referenced PodDisruptionBudget which doesn't exist (yet)
+func (v *QuorumGuardValidator) getQuorumGuardWarnings(ctx context.Context, guard *QuorumGuard) []string {
+	ref := guard.Spec.PodDisruptionBudget
+	if ref == nil {
+		return nil
+	}
+	if _, err := pdb.Get(ctx, v.client, v.policyV1, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return []string{fmt.Sprintf(WarningQuorumGuardPDBNotFound, ref.Namespace, ref.Name)}
+		}
+		nodemaintenancelog.Error(err, "could not get PodDisruptionBudget of quorum guard", "name", guard.Name)
+	}
+	return nil
+}
+
+// validateQuorumGuardSpec validates the spec of a QuorumGuard, it is used for denying invalid QuorumGuards,
+// and for denying maintenances which can't be validated because of an invalid QuorumGuard
+func validateQuorumGuardSpec(spec *QuorumGuardSpec) error {
+	if (spec.MinAvailable == nil) == (spec.PodDisruptionBudget == nil) {
+		return fmt.Errorf("exactly one of spec.minAvailable and spec.podDisruptionBudget must be set")
+	}
+	if spec.MinAvailable != nil && *spec.MinAvailable < 1 {
+		return fmt.Errorf("spec.minAvailable must be at least 1")
+	}
+	if ref := spec.PodDisruptionBudget; ref != nil && (ref.Namespace == "" || ref.Name == "") {
+		return fmt.Errorf("spec.podDisruptionBudget must have a namespace and a name")
+	}
+	if spec.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(spec.NodeSelector); err != nil {
+			return fmt.Errorf("invalid spec.nodeSelector: %v", err)
+		}
+	}
+	if spec.PodSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(spec.PodSelector); err != nil {
+			return fmt.Errorf("invalid spec.podSelector: %v", err)
+		}
+	}
+	return nil
+}
@@ -21,15 +21,51 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapReference.
+func (in *ConfigMapReference) DeepCopy() *ConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForeignLease) DeepCopyInto(out *ForeignLease) {
+	*out = *in
+	if in.AcquireTime != nil {
+		in, out := &in.AcquireTime, &out.AcquireTime
+		*out = (*in).DeepCopy()
+	}
+	in.ExpiryTime.DeepCopyInto(&out.ExpiryTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForeignLease.
+func (in *ForeignLease) DeepCopy() *ForeignLease {
+	if in == nil {
+		return nil
+	}
+	out := new(ForeignLease)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeMaintenance) DeepCopyInto(out *NodeMaintenance) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -83,9 +119,132 @@ func (in *NodeMaintenanceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceRecord) DeepCopyInto(out *NodeMaintenanceRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceRecord.
+func (in *NodeMaintenanceRecord) DeepCopy() *NodeMaintenanceRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeMaintenanceRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceRecordList) DeepCopyInto(out *NodeMaintenanceRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeMaintenanceRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceRecordList.
+func (in *NodeMaintenanceRecordList) DeepCopy() *NodeMaintenanceRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeMaintenanceRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMaintenanceRecordStatus) DeepCopyInto(out *NodeMaintenanceRecordStatus) {
+	*out = *in
+	if in.RequesterGroups != nil {
+		in, out := &in.RequesterGroups, &out.RequesterGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DrainCompletedTime != nil {
+		in, out := &in.DrainCompletedTime, &out.DrainCompletedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PhaseTransitions != nil {
+		in, out := &in.PhaseTransitions, &out.PhaseTransitions
+		*out = make([]PhaseTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EvictedPods != nil {
+		in, out := &in.EvictedPods, &out.EvictedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingPods != nil {
+		in, out := &in.PendingPods, &out.PendingPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceRecordStatus.
+func (in *NodeMaintenanceRecordStatus) DeepCopy() *NodeMaintenanceRecordStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMaintenanceRecordStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeMaintenanceSpec) DeepCopyInto(out *NodeMaintenanceSpec) {
 	*out = *in
+	if in.ForceDeleteTerminatingPodsAfter != nil {
+		in, out := &in.ForceDeleteTerminatingPodsAfter, &out.ForceDeleteTerminatingPodsAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PDBPolicy != nil {
+		in, out := &in.PDBPolicy, &out.PDBPolicy
+		*out = new(PDBPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DrainTimeout != nil {
+		in, out := &in.DrainTimeout, &out.DrainTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceSpec.
@@ -106,6 +265,73 @@ func (in *NodeMaintenanceStatus) DeepCopyInto(out *NodeMaintenanceStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.EvictedPods != nil {
+		in, out := &in.EvictedPods, &out.EvictedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodListConfigMap != nil {
+		in, out := &in.PodListConfigMap, &out.PodListConfigMap
+		*out = new(ConfigMapReference)
+		**out = **in
+	}
+	if in.PodEvictions != nil {
+		in, out := &in.PodEvictions, &out.PodEvictions
+		*out = make([]PodEvictionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DrainStartTime != nil {
+		in, out := &in.DrainStartTime, &out.DrainStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DrainCompletedTime != nil {
+		in, out := &in.DrainCompletedTime, &out.DrainCompletedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastReconcileTime != nil {
+		in, out := &in.LastReconcileTime, &out.LastReconcileTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LeaseExpiryTime != nil {
+		in, out := &in.LeaseExpiryTime, &out.LeaseExpiryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ForeignLease != nil {
+		in, out := &in.ForeignLease, &out.ForeignLease
+		*out = new(ForeignLease)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ForceDeletedPods != nil {
+		in, out := &in.ForceDeletedPods, &out.ForceDeletedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PDBBypassTime != nil {
+		in, out := &in.PDBBypassTime, &out.PDBBypassTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ViolatedPDBs != nil {
+		in, out := &in.ViolatedPDBs, &out.ViolatedPDBs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PhaseTransitions != nil {
+		in, out := &in.PhaseTransitions, &out.PhaseTransitions
+		*out = make([]PhaseTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMaintenanceStatus.
@@ -117,3 +343,179 @@ func (in *NodeMaintenanceStatus) DeepCopy() *NodeMaintenanceStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDBPolicy) DeepCopyInto(out *PDBPolicy) {
+	*out = *in
+	if in.BypassAfter != nil {
+		in, out := &in.BypassAfter, &out.BypassAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDBPolicy.
+func (in *PDBPolicy) DeepCopy() *PDBPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PDBPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PhaseTransition) DeepCopyInto(out *PhaseTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhaseTransition.
+func (in *PhaseTransition) DeepCopy() *PhaseTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(PhaseTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetReference) DeepCopyInto(out *PodDisruptionBudgetReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetReference.
+func (in *PodDisruptionBudgetReference) DeepCopy() *PodDisruptionBudgetReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodEvictionStatus) DeepCopyInto(out *PodEvictionStatus) {
+	*out = *in
+	in.FirstAttemptTime.DeepCopyInto(&out.FirstAttemptTime)
+	in.LastAttemptTime.DeepCopyInto(&out.LastAttemptTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodEvictionStatus.
+func (in *PodEvictionStatus) DeepCopy() *PodEvictionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PodEvictionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuorumGuard) DeepCopyInto(out *QuorumGuard) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuorumGuard.
+func (in *QuorumGuard) DeepCopy() *QuorumGuard {
+	if in == nil {
+		return nil
+	}
+	out := new(QuorumGuard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuorumGuard) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuorumGuardList) DeepCopyInto(out *QuorumGuardList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuorumGuard, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuorumGuardList.
+func (in *QuorumGuardList) DeepCopy() *QuorumGuardList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuorumGuardList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuorumGuardList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuorumGuardSpec) DeepCopyInto(out *QuorumGuardSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuorumGuardSpec.
+func (in *QuorumGuardSpec) DeepCopy() *QuorumGuardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuorumGuardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyLimit) DeepCopyInto(out *TopologyLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyLimit.
+func (in *TopologyLimit) DeepCopy() *TopologyLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyLimit)
+	in.DeepCopyInto(out)
+	return out
+}
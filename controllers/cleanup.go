@@ -0,0 +1,149 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodemaintenancev1 "kubevirt.io/node-maintenance-operator/api/v1"
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// CleanupReport describes what was done by Cleanup
+type CleanupReport struct {
+	// StoppedNodes are the nodes which were taken out of maintenance
+	StoppedNodes []string
+	// ReleasedLeases are the node leases of the operator which were released in addition to the leases of the stopped nodes
+	ReleasedLeases []string
+	// RemovedFinalizers are the NodeMaintenances whose finalizer was removed
+	RemovedFinalizers []string
+}
+
+// Cleanup ends all started maintenances, releases all node leases of the operator,
+// and removes the finalizer of all NodeMaintenances, so that the operator can be uninstalled
+// without leaving cordoned nodes and stuck NodeMaintenances behind.
+// The operator must not run during the cleanup, otherwise it restarts the maintenances.
+// Errors don't stop the cleanup, they are returned together after everything else was done.
+func Cleanup(ctx context.Context, c client.Client, config *rest.Config, leaseNamespace string) (*CleanupReport, error) {
+	r := &NodeMaintenanceReconciler{
+		Client:         c,
+		LeaseNamespace: leaseNamespace,
+		logger:         ctrl.Log.WithName("cleanup"),
+	}
+	if err := initDrainer(r, config); err != nil {
+		return nil, err
+	}
+	if err := r.checkLeaseSupported(); err != nil {
+		return nil, err
+	}
+	r.leaseManager = newLeaseManager(c, leaseNamespace)
+	return r.cleanup(ctx)
+}
+
+func (r *NodeMaintenanceReconciler) cleanup(ctx context.Context) (*CleanupReport, error) {
+	// NodeMaintenances are read in the storage version, because the conversion webhook of the operator is gone already
+	nmList := &nodemaintenancev1.NodeMaintenanceList{}
+	if err := r.Client.List(ctx, nmList); err != nil {
+		return nil, fmt.Errorf("could not list NodeMaintenances: %v", err)
+	}
+
+	report := &CleanupReport{}
+	var errs []string
+
+	stopped := map[string]bool{}
+	for i := range nmList.Items {
+		nm := &nmList.Items[i]
+		nodeName := nm.Spec.NodeName
+		// the conversion doesn't need the webhook, it is the same code which the webhook runs
+		converted := &nodemaintenancev1beta1.NodeMaintenance{}
+		if err := converted.ConvertFrom(nm); err != nil {
+			errs = append(errs, fmt.Sprintf("could not convert NodeMaintenance %s: %v", nm.Name, err))
+			continue
+		}
+		if !isMaintenanceStarted(converted) || stopped[nodeName] {
+			continue
+		}
+		if err := r.stopNodeMaintenanceOnDeletion(nodeName); err != nil {
+			errs = append(errs, fmt.Sprintf("could not stop maintenance of node %s: %v", nodeName, err))
+			continue
+		}
+		stopped[nodeName] = true
+		report.StoppedNodes = append(report.StoppedNodes, nodeName)
+	}
+
+	if r.isLeaseSupported {
+		released, err := r.releaseOperatorLeases(ctx)
+		report.ReleasedLeases = released
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	for i := range nmList.Items {
+		nm := &nmList.Items[i]
+		if !ContainsString(nm.Finalizers, nodemaintenancev1beta1.NodeMaintenanceFinalizer) {
+			continue
+		}
+		patch := client.MergeFrom(nm.DeepCopy())
+		nm.Finalizers = RemoveString(nm.Finalizers, nodemaintenancev1beta1.NodeMaintenanceFinalizer)
+		if err := r.Client.Patch(ctx, nm, patch); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Sprintf("could not remove finalizer of NodeMaintenance %s: %v", nm.Name, err))
+			continue
+		}
+		report.RemovedFinalizers = append(report.RemovedFinalizers, nm.Name)
+	}
+
+	if len(errs) > 0 {
+		return report, fmt.Errorf("cleanup failed: %s", strings.Join(errs, "; "))
+	}
+	return report, nil
+}
+
+// releaseOperatorLeases releases the node leases which are still held by the operator
+func (r *NodeMaintenanceReconciler) releaseOperatorLeases(ctx context.Context) ([]string, error) {
+	leases := &coordv1.LeaseList{}
+	if err := r.Client.List(ctx, leases, client.InNamespace(r.LeaseNamespace)); err != nil {
+		return nil, fmt.Errorf("could not list leases: %v", err)
+	}
+
+	var released []string
+	var errs []string
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		if !isNodeLease(lease) || getLeaseHolder(lease) != LeaseHolderIdentity || lease.Spec.RenewTime == nil {
+			continue
+		}
+		if err := r.leaseManager.Release(ctx, lease.Name); err != nil {
+			errs = append(errs, fmt.Sprintf("could not release lease of node %s: %v", lease.Name, err))
+			continue
+		}
+		released = append(released, lease.Name)
+	}
+
+	if len(errs) > 0 {
+		return released, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return released, nil
+}
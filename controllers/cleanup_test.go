@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/kubectl/pkg/drain"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nodemaintenancev1 "kubevirt.io/node-maintenance-operator/api/v1"
+	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+var _ = Describe("Cleanup", func() {
+
+	var r *NodeMaintenanceReconciler
+	var clientset *k8sfake.Clientset
+
+	getNode := func(name string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.NodeSpec{
+				Unschedulable: true,
+				Taints:        append([]corev1.Taint{}, MaintenanceTaints...),
+			},
+		}
+	}
+
+	// getNM returns a NodeMaintenance in the v1 storage version, which is read by the cleanup
+	getNM := func(name, nodeName string, phase nodemaintenanceapi.MaintenancePhase) *nodemaintenancev1.NodeMaintenance {
+		nm := getTestNM()
+		nm.Name = name
+		nm.Spec.NodeName = nodeName
+		nm.Finalizers = []string{nodemaintenanceapi.NodeMaintenanceFinalizer}
+		nm.Status.Phase = phase
+		hub := &nodemaintenancev1.NodeMaintenance{}
+		Expect(nm.ConvertTo(hub)).To(Succeed())
+		return hub
+	}
+
+	var rolledBack *nodemaintenancev1.NodeMaintenance
+
+	BeforeEach(func() {
+		maintained := getNode("node-maintained")
+		orphaned := getNode("node-orphaned")
+		ended := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-ended"}}
+		rolledBackNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-rolled-back"}}
+		rolledBack = getNM("nm-rolled-back", rolledBackNode.Name, nodemaintenanceapi.MaintenanceFailed)
+		rolledBack.Status.RolledBack = true
+
+		// only the v1 storage version is known, like on a cluster without the conversion webhook of the operator
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(nodemaintenancev1.AddToScheme(testScheme)).To(Succeed())
+		cl := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+			maintained, orphaned, ended, rolledBackNode,
+			getNM("nm-succeeded", maintained.Name, nodemaintenanceapi.MaintenanceSucceeded),
+			getNM("nm-running", maintained.Name, nodemaintenanceapi.MaintenanceRunning),
+			getNM("nm-ended", ended.Name, nodemaintenanceapi.MaintenanceEnded),
+			rolledBack,
+		).Build()
+		clientset = k8sfake.NewSimpleClientset(maintained, orphaned, ended, rolledBackNode)
+		r = &NodeMaintenanceReconciler{
+			Client:           cl,
+			Scheme:           testScheme,
+			LeaseNamespace:   LeaseNamespaceDefault,
+			logger:           ctrl.Log.WithName("cleanup"),
+			isLeaseSupported: true,
+			leaseManager:     newLeaseManager(cl, LeaseNamespaceDefault),
+			drainer:          &drain.Helper{Client: clientset, Ctx: context.Background()},
+		}
+		_, err := r.leaseManager.Acquire(context.TODO(), maintained, LeaseDuration)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = r.leaseManager.Acquire(context.TODO(), orphaned, LeaseDuration)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should end all maintenances, release all leases and remove all finalizers", func() {
+		report, err := r.cleanup(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.StoppedNodes).To(ConsistOf("node-maintained"))
+		Expect(report.ReleasedLeases).To(ConsistOf("node-orphaned"))
+		Expect(report.RemovedFinalizers).To(ConsistOf("nm-succeeded", "nm-running", "nm-ended", "nm-rolled-back"))
+
+		node, err := clientset.CoreV1().Nodes().Get(context.TODO(), "node-maintained", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+		Expect(hasDrainTaint(node)).To(BeFalse())
+
+		for _, nodeName := range []string{"node-maintained", "node-orphaned"} {
+			status, err := r.leaseManager.Check(context.TODO(), nodeName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Valid).To(BeFalse())
+		}
+
+		nmList := &nodemaintenancev1.NodeMaintenanceList{}
+		Expect(r.Client.List(context.TODO(), nmList)).To(Succeed())
+		for _, nm := range nmList.Items {
+			Expect(nm.Finalizers).To(BeEmpty())
+		}
+	})
+
+	It("should not touch nodes of ended and rolled back maintenances", func() {
+		_, err := r.cleanup(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, action := range clientset.Actions() {
+			if patch, ok := action.(k8stesting.PatchAction); ok {
+				Expect(patch.GetName()).NotTo(BeElementOf("node-ended", "node-rolled-back"))
+			}
+		}
+	})
+})
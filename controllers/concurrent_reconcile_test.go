@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/drain"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// run with the race detector, e.g. go test -race ./controllers/...
+var _ = Describe("Concurrent reconciles", func() {
+
+	const nodeCount = 5
+	const podsPerNode = 2
+
+	var r *NodeMaintenanceReconciler
+	var clientset *k8sfake.Clientset
+	var nms []*nodemaintenanceapi.NodeMaintenance
+
+	BeforeEach(func() {
+		var objs []client.Object
+		var k8sObjs []runtime.Object
+		nms = nil
+		for i := 0; i < nodeCount; i++ {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node%02d", i)}}
+			nm := &nodemaintenanceapi.NodeMaintenance{
+				ObjectMeta: metav1.ObjectMeta{Name: "nm-" + node.Name},
+				Spec:       nodemaintenanceapi.NodeMaintenanceSpec{NodeName: node.Name},
+			}
+			nms = append(nms, nm)
+			objs = append(objs, node, nm)
+			k8sObjs = append(k8sObjs, node)
+			for j := 0; j < podsPerNode; j++ {
+				k8sObjs = append(k8sObjs, &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: "default",
+						Name:      fmt.Sprintf("pod-%s-%d", node.Name, j),
+						OwnerReferences: []metav1.OwnerReference{{
+							APIVersion: "apps/v1",
+							Kind:       "ReplicaSet",
+							Name:       "rs",
+							Controller: pointer.Bool(true),
+						}},
+					},
+					Spec: corev1.PodSpec{NodeName: node.Name},
+				})
+			}
+		}
+
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(nodemaintenanceapi.AddToScheme(testScheme)).To(Succeed())
+		cl := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(objs...).Build()
+
+		clientset = k8sfake.NewSimpleClientset(k8sObjs...)
+		// the fake clientset doesn't implement evictions, delete the evicted pods instead
+		clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+			err := clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+			return true, nil, err
+		})
+		// the fake clientset ignores field selectors, filter the pods of the drained node
+		clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			obj, err := clientset.Tracker().List(corev1.SchemeGroupVersion.WithResource("pods"), corev1.SchemeGroupVersion.WithKind("Pod"), action.GetNamespace())
+			if err != nil {
+				return true, nil, err
+			}
+			podList := obj.(*corev1.PodList)
+			selector := action.(k8stesting.ListAction).GetListRestrictions().Fields
+			var pods []corev1.Pod
+			for _, pod := range podList.Items {
+				if selector.Matches(fields.Set{"spec.nodeName": pod.Spec.NodeName}) {
+					pods = append(pods, pod)
+				}
+			}
+			podList.Items = pods
+			return true, podList, nil
+		})
+
+		r = &NodeMaintenanceReconciler{
+			Client:           cl,
+			Scheme:           testScheme,
+			isLeaseSupported: true,
+			leaseManager:     newLeaseManager(cl, LeaseNamespaceDefault),
+			drainer:          &drain.Helper{Client: clientset, Ctx: context.Background()},
+			recorder:         record.NewFakeRecorder(100),
+			logger:           ctrl.Log.WithName("test"),
+		}
+	})
+
+	reconcileAll := func() {
+		var wg sync.WaitGroup
+		for _, nm := range nms {
+			wg.Add(1)
+			go func(nm *nodemaintenanceapi.NodeMaintenance) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nm)})
+				Expect(err).NotTo(HaveOccurred())
+			}(nm)
+		}
+		wg.Wait()
+	}
+
+	syncNodes := func() {
+		for _, nm := range nms {
+			node, err := clientset.CoreV1().Nodes().Get(context.TODO(), nm.Spec.NodeName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			cached := &corev1.Node{}
+			Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(node), cached)).To(Succeed())
+			cached.Spec = node.Spec
+			Expect(r.Client.Update(context.TODO(), cached)).To(Succeed())
+		}
+	}
+
+	It("should drain several nodes in parallel", func() {
+		reconcileAll()
+		// the cache catches up with the cordoned nodes, then the leases are renewed
+		syncNodes()
+		reconcileAll()
+
+		for _, nm := range nms {
+			maintenance := &nodemaintenanceapi.NodeMaintenance{}
+			Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(nm), maintenance)).To(Succeed())
+			Expect(maintenance.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceSucceeded))
+			Expect(maintenance.Status.EvictedPodsCount).To(Equal(podsPerNode))
+			Expect(maintenance.Status.PendingPods).To(BeEmpty())
+
+			node, err := clientset.CoreV1().Nodes().Get(context.TODO(), nm.Spec.NodeName, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(node.Spec.Unschedulable).To(BeTrue())
+		}
+		pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods.Items).To(BeEmpty())
+	})
+
+	It("should reconcile with a copy of the logger and drainer", func() {
+		reconciler := r.forRequest(context.TODO())
+		Expect(reconciler).NotTo(BeIdenticalTo(r))
+		Expect(reconciler.drainer).NotTo(BeIdenticalTo(r.drainer))
+		Expect(reconciler.drainer.Client).To(BeIdenticalTo(r.drainer.Client))
+
+		reconciler.drainer.DisableEviction = true
+		Expect(r.drainer.DisableEviction).To(BeFalse())
+	})
+})
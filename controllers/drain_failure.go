@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/drain"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// EventReasonDrainTimeout is the reason of the event emitted when the drain didn't complete within the drain timeout
+const EventReasonDrainTimeout = "DrainTimeout"
+
+// isDrainTimedOut returns true if the drain timeout of the maintenance passed since the drain started,
+// the time waiting for the lease of the node doesn't count
+func isDrainTimedOut(nm *nodemaintenancev1beta1.NodeMaintenance, now time.Time) bool {
+	if nm.Spec.DrainTimeout == nil || nm.Status.DrainStartTime == nil {
+		return false
+	}
+	return !now.Before(nm.Status.DrainStartTime.Add(nm.Spec.DrainTimeout.Duration))
+}
+
+// isDrainFailed returns true if the maintenance failed because of the drain timeout.
+// The failure is final, until the maintenance is restarted by setting it Inactive and Active again.
+func isDrainFailed(nm *nodemaintenancev1beta1.NodeMaintenance) bool {
+	return nm.Status.Phase == nodemaintenancev1beta1.MaintenanceFailed &&
+		nm.Status.FailureReason == nodemaintenancev1beta1.FailureReasonDrainTimeout
+}
+
+// failDrain moves the maintenance to the Failed phase after the drain timeout,
+// and takes the node out of maintenance if the Rollback failure policy was chosen
+func (r *NodeMaintenanceReconciler) failDrain(nm *nodemaintenancev1beta1.NodeMaintenance, drainErr error, pendingList *drain.PodDeleteList) (reconcile.Result, error) {
+	r.logger.Info("Drain did not complete within the drain timeout", "nodeName", nm.Spec.NodeName, "failurePolicy", nm.Spec.FailurePolicy)
+
+	if nm.Spec.FailurePolicy == nodemaintenancev1beta1.DrainFailurePolicyRollback {
+		if err := r.releaseNodeMaintenance(nm); err != nil && !errors.IsNotFound(err) {
+			return r.onReconcileError(nm, fmt.Errorf("failed to roll back maintenance after drain timeout: %v", err))
+		}
+		nm.Status.RolledBack = true
+		nm.Status.NodeCordoned = false
+		nm.Status.LeaseExpiryTime = nil
+	}
+
+	now := metav1.Now()
+	nm.Status.Phase = nodemaintenancev1beta1.MaintenanceFailed
+	nm.Status.FailureReason = nodemaintenancev1beta1.FailureReasonDrainTimeout
+	nm.Status.EndTime = &now
+
+	err := fmt.Errorf("drain did not complete within %s: %v", nm.Spec.DrainTimeout.Duration, drainErr)
+	r.recorder.Eventf(nm, corev1.EventTypeWarning, EventReasonDrainTimeout,
+		"Maintenance of node %s failed, rolled back: %t: %v", nm.Spec.NodeName, nm.Status.RolledBack, err)
+
+	// a node kept in maintenance still needs its lease renewed
+	renewLease := leaseRenewalDelay(nm.Status.LeaseExpiryTime)
+	return r.onDrainErrorWithRequeue(nm, err, &renewLease, pendingList)
+}
+
+// reconcileFailedDrain keeps a maintenance which failed because of the drain timeout failed.
+// The lease of a node which is kept cordoned is renewed, since the node is still in maintenance.
+func (r *NodeMaintenanceReconciler) reconcileFailedDrain(nm *nodemaintenancev1beta1.NodeMaintenance) (reconcile.Result, error) {
+	if nm.Status.RolledBack {
+		return reconcile.Result{}, nil
+	}
+
+	node, err := r.fetchNode(nm.Spec.NodeName)
+	if err != nil {
+		return r.onReconcileError(nm, err)
+	}
+	if nm.Status.LeaseExpiryTime, _, err = r.obtainLease(node); err != nil {
+		return r.onReconcileError(nm, err)
+	}
+	if err := r.updateStatus(nm); err != nil {
+		return r.onReconcileError(nm, err)
+	}
+	return reconcile.Result{RequeueAfter: leaseRenewalDelay(nm.Status.LeaseExpiryTime)}, nil
+}
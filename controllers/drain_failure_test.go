@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/drain"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+var _ = Describe("Drain failure", func() {
+
+	var r *NodeMaintenanceReconciler
+	var nm *nodemaintenanceapi.NodeMaintenance
+	var clientset *k8sfake.Clientset
+
+	BeforeEach(func() {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node01"},
+			Spec: corev1.NodeSpec{
+				Unschedulable: true,
+				Taints:        append([]corev1.Taint{}, MaintenanceTaints...),
+			},
+		}
+		nm = getTestNM()
+		startTime := metav1.NewTime(time.Now().Add(-time.Hour))
+		nm.Spec.DrainTimeout = &metav1.Duration{Duration: 30 * time.Minute}
+		nm.Status.Phase = nodemaintenanceapi.MaintenanceRunning
+		nm.Status.StartTime = &startTime
+		nm.Status.DrainStartTime = &startTime
+
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(nodemaintenanceapi.AddToScheme(testScheme)).To(Succeed())
+		cl := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(node, nm).Build()
+		clientset = k8sfake.NewSimpleClientset(node)
+		r = &NodeMaintenanceReconciler{
+			Client:           cl,
+			Scheme:           testScheme,
+			isLeaseSupported: true,
+			leaseManager:     newLeaseManager(cl, LeaseNamespaceDefault),
+			drainer:          &drain.Helper{Client: clientset, Ctx: context.Background()},
+			recorder:         record.NewFakeRecorder(10),
+			logger:           ctrl.Log.WithName("test"),
+		}
+		var err error
+		nm.Status.LeaseExpiryTime, _, err = r.obtainLease(node)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	getNode := func() *corev1.Node {
+		node, err := clientset.CoreV1().Nodes().Get(context.TODO(), nm.Spec.NodeName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return node
+	}
+
+	It("should time out the drain after the drain timeout only", func() {
+		Expect(isDrainTimedOut(nm, time.Now())).To(BeTrue())
+		Expect(isDrainTimedOut(nm, nm.Status.DrainStartTime.Add(time.Minute))).To(BeFalse())
+
+		nm.Spec.DrainTimeout = nil
+		Expect(isDrainTimedOut(nm, time.Now())).To(BeFalse())
+	})
+
+	It("should not count the time waiting for the lease", func() {
+		drainStartTime := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+		nm.Status.DrainStartTime = &drainStartTime
+		Expect(isDrainTimedOut(nm, time.Now())).To(BeFalse())
+		Expect(isDrainTimedOut(nm, drainStartTime.Add(nm.Spec.DrainTimeout.Duration))).To(BeTrue())
+
+		nm.Status.DrainStartTime = nil
+		Expect(isDrainTimedOut(nm, time.Now())).To(BeFalse())
+	})
+
+	It("should start the drain timeout when the lease was obtained", func() {
+		nm.Status.Phase = nodemaintenanceapi.MaintenanceWaitingForLease
+		nm.Status.DrainStartTime = nil
+		Expect(r.Client.Status().Update(context.TODO(), nm)).To(Succeed())
+
+		_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nm)})
+		Expect(err).NotTo(HaveOccurred())
+
+		maintenance := &nodemaintenanceapi.NodeMaintenance{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(nm), maintenance)).To(Succeed())
+		Expect(maintenance.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceSucceeded))
+		Expect(maintenance.Status.DrainStartTime).NotTo(BeNil())
+		Expect(maintenance.Status.DrainStartTime.Time).To(BeTemporally(">", maintenance.Status.StartTime.Time))
+		Expect(isDrainTimedOut(maintenance, time.Now())).To(BeFalse())
+	})
+
+	It("should keep the node cordoned by default", func() {
+		res, err := r.failDrain(nm, fmt.Errorf("pods pending"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+		Expect(nm.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceFailed))
+		Expect(nm.Status.FailureReason).To(Equal(nodemaintenanceapi.FailureReasonDrainTimeout))
+		Expect(nm.Status.RolledBack).To(BeFalse())
+		Expect(nm.Status.LastError).To(ContainSubstring("pods pending"))
+		Expect(isMaintenanceStarted(nm)).To(BeTrue())
+		Expect(getNode().Spec.Unschedulable).To(BeTrue())
+	})
+
+	It("should roll back the maintenance with the Rollback policy", func() {
+		nm.Spec.FailurePolicy = nodemaintenanceapi.DrainFailurePolicyRollback
+		res, err := r.failDrain(nm, fmt.Errorf("pods pending"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RequeueAfter).To(BeZero())
+
+		Expect(nm.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceFailed))
+		Expect(nm.Status.FailureReason).To(Equal(nodemaintenanceapi.FailureReasonDrainTimeout))
+		Expect(nm.Status.RolledBack).To(BeTrue())
+		Expect(nm.Status.LeaseExpiryTime).To(BeNil())
+		Expect(isMaintenanceStarted(nm)).To(BeFalse())
+		Expect(getNode().Spec.Unschedulable).To(BeFalse())
+
+		status, err := r.leaseManager.Check(context.TODO(), nm.Spec.NodeName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Valid).To(BeFalse())
+	})
+
+	It("should keep a failed maintenance failed", func() {
+		_, err := r.failDrain(nm, fmt.Errorf("pods pending"), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nm)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+		maintenance := &nodemaintenanceapi.NodeMaintenance{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(nm), maintenance)).To(Succeed())
+		Expect(maintenance.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceFailed))
+		Expect(maintenance.Status.LeaseExpiryTime).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// AddFieldIndexes registers the field indexes of NodeMaintenances and pods by the name of their node,
+// which are used by the controller and the webhook for reading the NodeMaintenances and pods of a node from the cache.
+// It must be called before the manager is started.
+func AddFieldIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := nodemaintenancev1beta1.AddNodeNameFieldIndex(ctx, indexer); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &corev1.Pod{}, nodemaintenancev1beta1.NodeNameField, func(obj client.Object) []string {
+		return []string{obj.(*corev1.Pod).Spec.NodeName}
+	})
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeIndexer records the registered field indexes by object type and field
+type fakeIndexer map[string]client.IndexerFunc
+
+func (f fakeIndexer) IndexField(_ context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	f[fmt.Sprintf("%T/%s", obj, field)] = extractValue
+	return nil
+}
+
+var _ = Describe("Field indexes", func() {
+
+	It("should index NodeMaintenances and pods by node name", func() {
+		indexer := fakeIndexer{}
+		Expect(AddFieldIndexes(context.TODO(), indexer)).To(Succeed())
+		Expect(indexer).To(HaveLen(2))
+
+		nm := getTestNM()
+		Expect(indexer["*v1beta1.NodeMaintenance/spec.nodeName"](nm)).To(Equal([]string{"node01"}))
+
+		pod := &corev1.Pod{Spec: corev1.PodSpec{NodeName: "node02"}}
+		Expect(indexer["*v1.Pod/spec.nodeName"](pod)).To(Equal([]string{"node02"}))
+	})
+})
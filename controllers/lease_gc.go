@@ -0,0 +1,200 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// DefaultLeaseGCInterval is the default interval of lease garbage collection
+	DefaultLeaseGCInterval = time.Hour
+	// DefaultInvalidLeaseRetention is the default time invalid leases are kept
+	DefaultInvalidLeaseRetention = 24 * time.Hour
+)
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=create
+//+kubebuilder:rbac:groups="coordination.k8s.io",resources=leases,verbs=delete
+
+// EnsureLeaseNamespace creates the given namespace of the node leases if it doesn't exist
+func EnsureLeaseNamespace(ctx context.Context, c client.Client, namespace string) error {
+	ns := &corev1.Namespace{}
+	err := c.Get(ctx, client.ObjectKey{Name: namespace}, ns)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("could not get lease namespace %s: %v", namespace, err)
+	}
+	ns.Name = namespace
+	if err := c.Create(ctx, ns); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("could not create lease namespace %s: %v", namespace, err)
+	}
+	return nil
+}
+
+// LeaseGarbageCollector periodically deletes node leases in the LeaseNamespace of nodes which don't exist anymore,
+// and node leases which are invalid for longer than the retention time.
+// Only leases owned by a node are considered, other leases in the namespace, e.g. for leader election, are ignored.
+type LeaseGarbageCollector struct {
+	client.Client
+	// LeaseNamespace is the namespace of the node leases
+	LeaseNamespace string
+	// Interval is the interval of the garbage collection
+	Interval time.Duration
+	// Retention is the time invalid leases are kept
+	Retention time.Duration
+
+	// invalidSince holds the time released leases were found invalid first, since they don't carry that time
+	invalidSince map[string]time.Time
+}
+
+var _ manager.Runnable = &LeaseGarbageCollector{}
+var _ manager.LeaderElectionRunnable = &LeaseGarbageCollector{}
+
+// SetupWithManager adds the garbage collector to the Manager
+func (gc *LeaseGarbageCollector) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(gc)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only the leader deletes leases
+func (gc *LeaseGarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable, it collects garbage until the context is done
+func (gc *LeaseGarbageCollector) Start(ctx context.Context) error {
+	logger := ctrl.Log.WithName("lease-gc")
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := gc.collect(ctx, time.Now()); err != nil {
+			logger.Error(err, "failed to collect leases")
+		}
+	}, gc.Interval)
+	return nil
+}
+
+// collect deletes the node leases of missing nodes and the node leases which are invalid for longer than the retention time
+func (gc *LeaseGarbageCollector) collect(ctx context.Context, now time.Time) error {
+	logger := ctrl.Log.WithName("lease-gc")
+	if gc.invalidSince == nil {
+		gc.invalidSince = map[string]time.Time{}
+	}
+
+	leases := &coordv1.LeaseList{}
+	if err := gc.List(ctx, leases, client.InNamespace(gc.LeaseNamespace)); err != nil {
+		return fmt.Errorf("could not list leases: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		if !isNodeLease(lease) {
+			continue
+		}
+		seen[lease.Name] = true
+
+		reason := ""
+		nodeExists, err := gc.nodeExists(ctx, lease.Name)
+		if err != nil {
+			return err
+		}
+		if !nodeExists {
+			reason = "node does not exist"
+		} else if since := gc.getInvalidSince(lease, now); since != nil && now.Sub(*since) > gc.Retention {
+			reason = fmt.Sprintf("lease is invalid since %s", since.UTC().Format(time.RFC3339))
+		}
+		if reason == "" {
+			continue
+		}
+
+		// don't delete leases which were updated since they were listed, e.g. acquired again
+		preconditions := client.Preconditions{UID: &lease.UID, ResourceVersion: &lease.ResourceVersion}
+		if err := gc.Delete(ctx, lease, preconditions); err != nil {
+			if errors.IsNotFound(err) || errors.IsConflict(err) {
+				continue
+			}
+			return fmt.Errorf("could not delete lease %s: %v", lease.Name, err)
+		}
+		logger.Info("deleted lease", "name", lease.Name, "holder", getLeaseHolder(lease), "reason", reason)
+		delete(gc.invalidSince, lease.Name)
+	}
+
+	for name := range gc.invalidSince {
+		if !seen[name] {
+			delete(gc.invalidSince, name)
+		}
+	}
+	return nil
+}
+
+// getInvalidSince returns the time the lease became invalid, or nil if it is valid
+func (gc *LeaseGarbageCollector) getInvalidSince(lease *coordv1.Lease, now time.Time) *time.Time {
+	if lease.Spec.RenewTime != nil && lease.Spec.LeaseDurationSeconds != nil {
+		delete(gc.invalidSince, lease.Name)
+		dueTime := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+		if dueTime.After(now) {
+			return nil
+		}
+		return &dueTime
+	}
+
+	// released lease
+	since, found := gc.invalidSince[lease.Name]
+	if !found {
+		since = now
+		gc.invalidSince[lease.Name] = since
+	}
+	return &since
+}
+
+func (gc *LeaseGarbageCollector) nodeExists(ctx context.Context, nodeName string) (bool, error) {
+	node := &corev1.Node{}
+	if err := gc.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not get node %s: %v", nodeName, err)
+	}
+	return true, nil
+}
+
+// isNodeLease returns true if the lease is owned by a node
+func isNodeLease(lease *coordv1.Lease) bool {
+	for _, owner := range lease.OwnerReferences {
+		if owner.Kind == "Node" && owner.APIVersion == corev1.SchemeGroupVersion.Version {
+			return true
+		}
+	}
+	return false
+}
+
+func getLeaseHolder(lease *coordv1.Lease) string {
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
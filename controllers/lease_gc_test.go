@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Lease Garbage Collection", func() {
+
+	const retention = time.Hour
+
+	var cl client.Client
+	var gc *LeaseGarbageCollector
+
+	getNode := func(name string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	getLease := func(name string, renewTime *time.Time) *coordv1.Lease {
+		lease := &coordv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: LeaseNamespaceDefault,
+				Name:      name,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "v1",
+					Kind:       "Node",
+					Name:       name,
+				}},
+			},
+			Spec: coordv1.LeaseSpec{
+				HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
+				LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+			},
+		}
+		if renewTime != nil {
+			lease.Spec.RenewTime = &metav1.MicroTime{Time: *renewTime}
+		}
+		return lease
+	}
+
+	leaseExists := func(name string) bool {
+		err := cl.Get(context.TODO(), client.ObjectKey{Namespace: LeaseNamespaceDefault, Name: name}, &coordv1.Lease{})
+		if errors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return true
+	}
+
+	setup := func(objs ...client.Object) {
+		cl = fake.NewClientBuilder().WithObjects(objs...).Build()
+		gc = &LeaseGarbageCollector{
+			Client:         cl,
+			LeaseNamespace: LeaseNamespaceDefault,
+			Interval:       time.Minute,
+			Retention:      retention,
+		}
+	}
+
+	It("should delete leases of missing nodes", func() {
+		now := time.Now()
+		setup(getLease("node-gone", &now))
+		Expect(gc.collect(context.TODO(), now)).To(Succeed())
+		Expect(leaseExists("node-gone")).To(BeFalse())
+	})
+
+	It("should keep valid leases and recently expired leases of existing nodes", func() {
+		now := time.Now()
+		expired := now.Add(-LeaseDuration - retention/2)
+		setup(getNode("node-valid"), getLease("node-valid", &now), getNode("node-expired"), getLease("node-expired", &expired))
+		Expect(gc.collect(context.TODO(), now)).To(Succeed())
+		Expect(leaseExists("node-valid")).To(BeTrue())
+		Expect(leaseExists("node-expired")).To(BeTrue())
+	})
+
+	It("should delete leases which expired longer than the retention time ago", func() {
+		now := time.Now()
+		expired := now.Add(-LeaseDuration - 2*retention)
+		setup(getNode("node-expired"), getLease("node-expired", &expired))
+		Expect(gc.collect(context.TODO(), now)).To(Succeed())
+		Expect(leaseExists("node-expired")).To(BeFalse())
+	})
+
+	It("should delete released leases after the retention time", func() {
+		now := time.Now()
+		setup(getNode("node-released"), getLease("node-released", nil))
+		Expect(gc.collect(context.TODO(), now)).To(Succeed())
+		Expect(leaseExists("node-released")).To(BeTrue())
+
+		Expect(gc.collect(context.TODO(), now.Add(retention/2))).To(Succeed())
+		Expect(leaseExists("node-released")).To(BeTrue())
+
+		Expect(gc.collect(context.TODO(), now.Add(2*retention))).To(Succeed())
+		Expect(leaseExists("node-released")).To(BeFalse())
+	})
+
+	It("should ignore leases which aren't owned by a node", func() {
+		lease := getLease("leader-election", nil)
+		lease.OwnerReferences = nil
+		setup(lease)
+		Expect(gc.collect(context.TODO(), time.Now().Add(2*retention))).To(Succeed())
+		Expect(leaseExists("leader-election")).To(BeTrue())
+	})
+
+	It("should create the lease namespace", func() {
+		setup()
+		Expect(EnsureLeaseNamespace(context.TODO(), cl, LeaseNamespaceDefault)).To(Succeed())
+		Expect(cl.Get(context.TODO(), client.ObjectKey{Name: LeaseNamespaceDefault}, &corev1.Namespace{})).To(Succeed())
+		Expect(EnsureLeaseNamespace(context.TODO(), cl, LeaseNamespaceDefault)).To(Succeed())
+	})
+})
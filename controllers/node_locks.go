@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"sync"
+)
+
+// nodeLocks serializes the reconciles of the NodeMaintenances of the same node.
+// With concurrent reconciles, a NodeMaintenance which releases a node could race with another one which holds it,
+// and uncordon the node right after the other one cordoned it.
+type nodeLocks struct {
+	mutex sync.Mutex
+	locks map[string]*nodeLock
+}
+
+// nodeLock is the lock of a single node, with the number of reconciles holding or waiting for it
+type nodeLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newNodeLocks() *nodeLocks {
+	return &nodeLocks{locks: map[string]*nodeLock{}}
+}
+
+// lock locks the given node, and returns the function for unlocking it.
+// A nil nodeLocks doesn't lock, for reconcilers which aren't set up with a manager.
+func (l *nodeLocks) lock(nodeName string) (unlock func()) {
+	if l == nil {
+		return func() {}
+	}
+
+	l.mutex.Lock()
+	nl, found := l.locks[nodeName]
+	if !found {
+		nl = &nodeLock{}
+		l.locks[nodeName] = nl
+	}
+	nl.refs++
+	l.mutex.Unlock()
+
+	nl.Lock()
+	return func() {
+		nl.Unlock()
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		nl.refs--
+		if nl.refs == 0 {
+			delete(l.locks, nodeName)
+		}
+	}
+}
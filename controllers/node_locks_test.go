@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node locks", func() {
+
+	var locks *nodeLocks
+
+	BeforeEach(func() {
+		locks = newNodeLocks()
+	})
+
+	lockAsync := func(nodeName string) <-chan func() {
+		locked := make(chan func(), 1)
+		go func() {
+			locked <- locks.lock(nodeName)
+		}()
+		return locked
+	}
+
+	It("should serialize reconciles of the same node", func() {
+		unlock := locks.lock("node01")
+		locked := lockAsync("node01")
+		Consistently(locked, 200*time.Millisecond).ShouldNot(Receive())
+
+		unlock()
+		var unlockSecond func()
+		Eventually(locked).Should(Receive(&unlockSecond))
+		unlockSecond()
+		Expect(locks.locks).To(BeEmpty())
+	})
+
+	It("should not serialize reconciles of different nodes", func() {
+		unlock := locks.lock("node01")
+		defer unlock()
+		var unlockOther func()
+		Eventually(lockAsync("node02")).Should(Receive(&unlockOther))
+		unlockOther()
+		Expect(locks.locks).To(HaveLen(1))
+	})
+
+	It("should not lock without node locks", func() {
+		var noLocks *nodeLocks
+		noLocks.lock("node01")()
+	})
+})
@@ -24,22 +24,27 @@ import (
 	"github.com/go-logr/logr"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/drain"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+	"kubevirt.io/node-maintenance-operator/pkg/lease"
+	"kubevirt.io/node-maintenance-operator/pkg/pdb"
 )
 
 const (
@@ -47,15 +52,32 @@ const (
 	DrainerTimeout                    = 30 * time.Second
 	WaitDurationOnDrainError          = 5 * time.Second
 	FixedDurationReconcileLog         = "Reconciling with fixed duration"
+	MaxPhaseTransitions               = 20
+	// LastReconcileTimeInterval is the minimal interval between status updates which only bump the last reconcile time
+	LastReconcileTimeInterval = time.Minute
 )
 
-// NodeMaintenanceReconciler reconciles a NodeMaintenance object
+// NodeMaintenanceReconciler reconciles a NodeMaintenance object.
+// Every request is reconciled by a copy of the reconciler with its own logger and drainer, see forRequest,
+// so that several NodeMaintenances can be reconciled concurrently. NodeMaintenances of the same node are reconciled
+// one after the other, see nodeLocks.
 type NodeMaintenanceReconciler struct {
 	client.Client
-	Scheme           *runtime.Scheme
-	drainer          *drain.Helper
-	isLeaseSupported bool
-	logger           logr.Logger
+	Scheme                  *runtime.Scheme
+	SpillPodLists           bool
+	MaxConcurrentReconciles int
+	// LeaseNamespace is the namespace of the node leases and of the pod list ConfigMaps, i.e. the operator namespace
+	LeaseNamespace      string
+	drainer             *drain.Helper
+	isLeaseSupported    bool
+	isPolicyV1Supported bool
+	leaseManager        lease.Manager
+	recorder            record.EventRecorder
+	logger              logr.Logger
+	// lastStatus is the status of the NodeMaintenance as it was read or last updated by the request
+	lastStatus *nodemaintenancev1beta1.NodeMaintenanceStatus
+	// nodeLocks is shared by the copies of the reconciler
+	nodeLocks *nodeLocks
 }
 
 //+kubebuilder:rbac:groups=nodemaintenance.kubevirt.io,resources=nodemaintenances,verbs=get;list;watch;create;update;patch;delete
@@ -64,7 +86,7 @@ type NodeMaintenanceReconciler struct {
 
 // TODO check if all these are really needed!
 //+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;update;patch;watch
-//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
 //+kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
 //+kubebuilder:rbac:groups="",resources=namespaces,verbs=get
 //+kubebuilder:rbac:groups="apps",resources=deployments;daemonsets;replicasets;statefulsets,verbs=get;list;watch
@@ -83,7 +105,7 @@ type NodeMaintenanceReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
 func (r *NodeMaintenanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.logger = log.FromContext(ctx)
+	r = r.forRequest(ctx)
 	r.logger.Info("Reconciling NodeMaintenance")
 
 	// Fetch the NodeMaintenance instance
@@ -101,6 +123,10 @@ func (r *NodeMaintenanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		r.logger.Info("Error reading the request object, requeuing.")
 		return reconcile.Result{}, err
 	}
+	r.lastStatus = instance.Status.DeepCopy()
+
+	unlock := r.nodeLocks.lock(instance.Spec.NodeName)
+	defer unlock()
 
 	// Add finalizer when object is created
 	if instance.ObjectMeta.DeletionTimestamp.IsZero() {
@@ -116,13 +142,22 @@ func (r *NodeMaintenanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		// The object is being deleted
 		if ContainsString(instance.ObjectMeta.Finalizers, nodemaintenancev1beta1.NodeMaintenanceFinalizer) || ContainsString(instance.ObjectMeta.Finalizers, metav1.FinalizerOrphanDependents) {
 			// Stop node maintenance - uncordon and remove live migration taint from the node.
-			if err := r.stopNodeMaintenanceOnDeletion(instance.Spec.NodeName); err != nil {
-				r.logger.Error(err, "error stopping node maintenance")
-				if errors.IsNotFound(err) == false {
-					return r.onReconcileError(instance, err)
+			// Nothing to do if the maintenance never started or was already ended.
+			if isMaintenanceStarted(instance) {
+				if err := r.releaseNodeMaintenance(instance); err != nil {
+					r.logger.Error(err, "error stopping node maintenance")
+					if errors.IsNotFound(err) == false {
+						return r.onReconcileError(instance, err)
+					}
 				}
 			}
 
+			// Keep the history of the maintenance before the NodeMaintenance is gone.
+			if err := r.recordMaintenance(instance); err != nil {
+				r.logger.Error(err, "error recording node maintenance")
+				return r.onReconcileError(instance, err)
+			}
+
 			// Remove our finalizer from the list and update it.
 			instance.ObjectMeta.Finalizers = RemoveString(instance.ObjectMeta.Finalizers, nodemaintenancev1beta1.NodeMaintenanceFinalizer)
 			if err := r.Client.Update(context.Background(), instance); err != nil {
@@ -132,6 +167,19 @@ func (r *NodeMaintenanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return reconcile.Result{}, nil
 	}
 
+	if !instance.Spec.IsActive() {
+		return r.endNodeMaintenance(instance)
+	}
+
+	if instance.Status.Phase == nodemaintenancev1beta1.MaintenanceEnded {
+		r.logger.Info("Restarting ended maintenance", "node", instance.Spec.NodeName)
+		resetMaintenanceStatus(instance)
+	}
+
+	if isDrainFailed(instance) {
+		return r.reconcileFailedDrain(instance)
+	}
+
 	err = r.initMaintenanceStatus(instance)
 	if err != nil {
 		r.logger.Error(err, "Failed to update NodeMaintenance with \"Running\" status")
@@ -148,7 +196,7 @@ func (r *NodeMaintenanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 
 	r.setOwnerRefToNode(instance, node)
 
-	updateOwnedLeaseFailed, err := r.obtainLease(node)
+	leaseExpiryTime, updateOwnedLeaseFailed, err := r.obtainLease(node)
 	if err != nil && updateOwnedLeaseFailed {
 		instance.Status.ErrorOnLeaseCount += 1
 		if instance.Status.ErrorOnLeaseCount > MaxAllowedErrorToUpdateOwnedLease {
@@ -159,20 +207,37 @@ func (r *NodeMaintenanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			if err != nil {
 				return r.onReconcileError(instance, fmt.Errorf("Failed to uncordon upon failure to obtain owned lease : %v ", err))
 			}
+			instance.Status.NodeCordoned = false
 			instance.Status.Phase = nodemaintenancev1beta1.MaintenanceFailed
+			instance.Status.FailureReason = nodemaintenancev1beta1.FailureReasonLeaseRenewal
+			now := metav1.Now()
+			instance.Status.EndTime = &now
+			instance.Status.LeaseExpiryTime = nil
 		}
 		return r.onReconcileError(instance, fmt.Errorf("Failed to extend lease owned by us : %v errorOnLeaseCount %d", err, instance.Status.ErrorOnLeaseCount))
 	}
 	if err != nil {
 		instance.Status.ErrorOnLeaseCount = 0
+		if heldErr, ok := err.(*lease.AlreadyHeldError); ok {
+			return r.waitForLease(instance, heldErr)
+		}
 		return r.onReconcileError(instance, err)
 	} else {
 		if instance.Status.Phase != nodemaintenancev1beta1.MaintenanceRunning || instance.Status.ErrorOnLeaseCount != 0 {
 			instance.Status.Phase = nodemaintenancev1beta1.MaintenanceRunning
 			instance.Status.ErrorOnLeaseCount = 0
+			instance.Status.EndTime = nil
+			instance.Status.FailureReason = ""
+		}
+		if instance.Status.DrainStartTime == nil {
+			now := metav1.Now()
+			instance.Status.DrainStartTime = &now
 		}
+		instance.Status.ForeignLease = nil
 	}
 
+	instance.Status.LeaseExpiryTime = leaseExpiryTime
+
 	// Cordon node
 	err = AddOrRemoveTaint(r.drainer.Client, node, true)
 	if err != nil {
@@ -182,26 +247,66 @@ func (r *NodeMaintenanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	if err = drain.RunCordonOrUncordon(r.drainer, node, true); err != nil {
 		return r.onReconcileError(instance, err)
 	}
+	instance.Status.NodeCordoned = true
 
-	r.logger.Info("Evict all Pods from Node", "nodeName", nodeName)
+	instance.Status.NodeUnreachable = isNodeUnreachable(node)
+	if instance.Status.NodeUnreachable && instance.Spec.ForceDeleteTerminatingPodsAfter != nil {
+		if err = r.forceDeleteTerminatingPods(instance, instance.Spec.ForceDeleteTerminatingPodsAfter.Duration); err != nil {
+			return r.onReconcileError(instance, err)
+		}
+	}
+
+	drainer := r.getDrainer(node)
+	if isPDBBypassDue(instance, time.Now()) {
+		podList, err := r.getPodsForDeletion(nodeName)
+		if err != nil {
+			return r.onReconcileError(instance, err)
+		}
+		if err = r.recordViolatedPDBs(instance, podList); err != nil {
+			return r.onReconcileError(instance, err)
+		}
+		drainer.DisableEviction = true
+	}
 
-	if err = drain.RunNodeDrain(r.drainer, nodeName); err != nil {
+	r.logger.Info("Evict all Pods from Node", "nodeName", nodeName, "unreachable", instance.Status.NodeUnreachable, "bypassPDBs", drainer.DisableEviction)
+
+	// RunNodeDrain lists the pods of the node on its own, without the cache, since the drain helper doesn't accept a pod list
+	if err = drain.RunNodeDrain(drainer, nodeName); err != nil {
 		r.logger.Info("Not all pods evicted", "nodeName", nodeName, "error", err)
+		// the pods which weren't evicted are listed once for the eviction status, the pending pods, and the failure
+		pendingList, listErr := r.getPodsForDeletion(nodeName)
+		if listErr != nil {
+			r.logger.Error(listErr, "Failed to list pending pods", "nodeName", nodeName)
+		} else {
+			r.updatePodEvictions(instance, pendingList, drainer.DisableEviction)
+		}
+		if isDrainTimedOut(instance, time.Now()) {
+			return r.failDrain(instance, err, pendingList)
+		}
 		waitOnReconcile := WaitDurationOnDrainError
-		return r.onReconcileErrorWithRequeue(instance, err, &waitOnReconcile)
+		return r.onDrainErrorWithRequeue(instance, err, &waitOnReconcile, pendingList)
 	}
 	r.logger.Info("All pods evicted", "nodeName", nodeName)
 
 	instance.Status.Phase = nodemaintenancev1beta1.MaintenanceSucceeded
-	instance.Status.PendingPods = nil
-	err = r.Client.Status().Update(context.TODO(), instance)
+	if err = r.setPendingPods(instance, nil); err != nil {
+		r.logger.Error(err, "Failed to update pod lists", "nodeName", nodeName)
+	}
+	instance.Status.PodEvictions = nil
+	instance.Status.FailedPodsCount = 0
+	if instance.Status.DrainCompletedTime == nil {
+		now := metav1.Now()
+		instance.Status.DrainCompletedTime = &now
+	}
+	err = r.updateStatus(instance)
 	if err != nil {
 		r.logger.Error(err, "Failed to update NodeMaintenance with \"Succeeded\" status")
 		return r.onReconcileError(instance, err)
 	}
 	r.logger.Info("Reconcile completed", "nodeName", nodeName)
 
-	return reconcile.Result{}, nil
+	// The maintenance is done, but the lease needs to be renewed until the maintenance ends
+	return reconcile.Result{RequeueAfter: leaseRenewalDelay(instance.Status.LeaseExpiryTime)}, nil
 
 }
 
@@ -215,11 +320,30 @@ func (r *NodeMaintenanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err != nil {
 		return err
 	}
+	err = r.checkPolicyV1Supported()
+	if err != nil {
+		return err
+	}
+	r.leaseManager = newLeaseManager(r.Client, r.LeaseNamespace)
+	r.recorder = mgr.GetEventRecorderFor("node-maintenance")
+	r.nodeLocks = newNodeLocks()
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&nodemaintenancev1beta1.NodeMaintenance{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
+// forRequest returns a copy of the reconciler for reconciling a single request, with the logger of the request
+// and a copy of the drainer bound to the context of the request. Concurrent reconciles don't share mutable state this way.
+func (r *NodeMaintenanceReconciler) forRequest(ctx context.Context) *NodeMaintenanceReconciler {
+	reconciler := *r
+	reconciler.logger = log.FromContext(ctx)
+	drainer := *r.drainer
+	drainer.Ctx = ctx
+	reconciler.drainer = &drainer
+	return &reconciler
+}
+
 func onPodDeletedOrEvicted(pod *corev1.Pod, usingEviction bool) {
 	var verbString string
 	if usingEviction {
@@ -231,10 +355,6 @@ func onPodDeletedOrEvicted(pod *corev1.Pod, usingEviction bool) {
 	klog.Info(msg)
 }
 
-func SetLeaseNamespace(namespace string) {
-	LeaseNamespace = namespace
-}
-
 func initDrainer(r *NodeMaintenanceReconciler, config *rest.Config) error {
 
 	r.drainer = &drain.Helper{}
@@ -280,7 +400,7 @@ func initDrainer(r *NodeMaintenanceReconciler, config *rest.Config) error {
 }
 
 func (r *NodeMaintenanceReconciler) checkLeaseSupported() error {
-	isLeaseSupported, err := checkLeaseSupportedInternal(r.drainer.Client)
+	isLeaseSupported, err := lease.IsSupported(r.drainer.Client)
 	if err != nil {
 		r.logger.Error(err, "Failed to check for lease support")
 		return err
@@ -289,6 +409,16 @@ func (r *NodeMaintenanceReconciler) checkLeaseSupported() error {
 	return nil
 }
 
+func (r *NodeMaintenanceReconciler) checkPolicyV1Supported() error {
+	isPolicyV1Supported, err := pdb.IsPolicyV1Supported(r.drainer.Client.Discovery())
+	if err != nil {
+		r.logger.Error(err, "Failed to check for policy/v1 support")
+		return err
+	}
+	r.isPolicyV1Supported = isPolicyV1Supported
+	return nil
+}
+
 func (r *NodeMaintenanceReconciler) setOwnerRefToNode(instance *nodemaintenancev1beta1.NodeMaintenance, node *corev1.Node) {
 
 	for _, ref := range instance.ObjectMeta.GetOwnerReferences() {
@@ -312,31 +442,70 @@ func (r *NodeMaintenanceReconciler) setOwnerRefToNode(instance *nodemaintenancev
 	instance.ObjectMeta.SetOwnerReferences(append(instance.ObjectMeta.GetOwnerReferences(), ref))
 }
 
-func (r *NodeMaintenanceReconciler) obtainLease(node *corev1.Node) (bool, error) {
+// obtainLease acquires or renews the lease of the given node and returns the time it expires,
+// or nil if leases aren't supported
+func (r *NodeMaintenanceReconciler) obtainLease(node *corev1.Node) (*metav1.Time, bool, error) {
 	if !r.isLeaseSupported {
-		return false, nil
+		return nil, false, nil
 	}
 
 	r.logger.Info("Lease object supported, obtaining lease")
-	lease, needUpdate, err := createOrGetExistingLease(r.Client, node, LeaseDuration)
-
+	dueTime, err := r.leaseManager.Acquire(context.TODO(), node, LeaseDuration)
 	if err != nil {
-		r.logger.Error(err, "failed to create or get existing lease")
-		return false, err
+		r.logger.Error(err, "failed to obtain lease")
+		return nil, lease.IsRenewError(err), err
 	}
 
-	if needUpdate {
+	expiryTime := metav1.NewTime(dueTime)
+	return &expiryTime, false, nil
+}
 
-		r.logger.Info("update lease")
+// waitForLease records the lease of the node held by another component in the status of the given NodeMaintenance,
+// and requeues it at the expiry of the lease
+func (r *NodeMaintenanceReconciler) waitForLease(nm *nodemaintenancev1beta1.NodeMaintenance, heldErr *lease.AlreadyHeldError) (reconcile.Result, error) {
+	r.logger.Info("Lease of node is held by another holder, waiting for its expiry", "node", heldErr.NodeName, "holder", heldErr.HolderIdentity, "expiry", heldErr.DueTime)
+
+	nm.Status.Phase = nodemaintenancev1beta1.MaintenanceWaitingForLease
+	nm.Status.LeaseExpiryTime = nil
+	// the drain starts over once the lease is obtained
+	nm.Status.DrainStartTime = nil
+	nm.Status.ForeignLease = &nodemaintenancev1beta1.ForeignLease{
+		HolderIdentity: heldErr.HolderIdentity,
+		ExpiryTime:     metav1.NewTime(heldErr.DueTime),
+	}
+	if heldErr.AcquireTime != nil {
+		acquireTime := metav1.NewTime(*heldErr.AcquireTime)
+		nm.Status.ForeignLease.AcquireTime = &acquireTime
+	}
 
-		now := metav1.NowMicro()
-		if err, updateOwnedLeaseFailed := updateLease(r.Client, node, lease, &now, LeaseDuration); err != nil {
-			return updateOwnedLeaseFailed, err
-		}
+	waitForExpiry := foreignLeaseWaitDuration(heldErr.DueTime)
+	return r.onReconcileErrorWithRequeue(nm, heldErr, &waitForExpiry)
+}
+
+// foreignLeaseWaitDuration returns the duration until a foreign lease with the given due time can be taken over
+func foreignLeaseWaitDuration(dueTime time.Time) time.Duration {
+	// the lease is still valid at its due time
+	wait := time.Until(dueTime) + time.Second
+	if wait < time.Second {
+		return time.Second
 	}
+	return wait
+}
 
-	return false, nil
+// leaseRenewalDelay returns the delay until the lease with the given expiry time needs to be renewed,
+// or zero if there is no lease.
+// Renewing one drainer timeout before expiry is within the renew margin of the lease manager.
+func leaseRenewalDelay(leaseExpiryTime *metav1.Time) time.Duration {
+	if leaseExpiryTime == nil {
+		return 0
+	}
+	delay := time.Until(leaseExpiryTime.Time) - DrainerTimeout
+	if delay < time.Second {
+		return time.Second
+	}
+	return delay
 }
+
 func (r *NodeMaintenanceReconciler) stopNodeMaintenanceImp(node *corev1.Node) error {
 	// Uncordon the node
 	err := AddOrRemoveTaint(r.drainer.Client, node, false)
@@ -349,7 +518,8 @@ func (r *NodeMaintenanceReconciler) stopNodeMaintenanceImp(node *corev1.Node) er
 	}
 
 	if r.isLeaseSupported {
-		if err := invalidateLease(r.Client, node.Name); err != nil {
+		r.logger.Info("Lease object supported, invalidating lease")
+		if err := r.leaseManager.Release(context.TODO(), node.Name); err != nil {
 			return err
 		}
 	}
@@ -362,7 +532,7 @@ func (r *NodeMaintenanceReconciler) stopNodeMaintenanceOnDeletion(nodeName strin
 		// if CR is gathered as result of garbage collection: the node may have been deleted, but the CR has not yet been deleted, still we must clean up the lease!
 		if errors.IsNotFound(err) {
 			if r.isLeaseSupported {
-				if err := invalidateLease(r.Client, nodeName); err != nil {
+				if err := r.leaseManager.Release(context.TODO(), nodeName); err != nil {
 					return err
 				}
 			}
@@ -373,8 +543,90 @@ func (r *NodeMaintenanceReconciler) stopNodeMaintenanceOnDeletion(nodeName strin
 	return r.stopNodeMaintenanceImp(node)
 }
 
+// endNodeMaintenance stops the maintenance of a NodeMaintenance with Inactive state, and moves it to the Ended phase
+func (r *NodeMaintenanceReconciler) endNodeMaintenance(nm *nodemaintenancev1beta1.NodeMaintenance) (reconcile.Result, error) {
+	if nm.Status.Phase == nodemaintenancev1beta1.MaintenanceEnded {
+		return reconcile.Result{}, nil
+	}
+
+	if isMaintenanceStarted(nm) {
+		r.logger.Info("Ending maintenance", "node", nm.Spec.NodeName)
+		if err := r.releaseNodeMaintenance(nm); err != nil && !errors.IsNotFound(err) {
+			r.logger.Error(err, "error ending node maintenance")
+			return r.onReconcileError(nm, err)
+		}
+	}
+
+	now := metav1.Now()
+	nm.Status.NodeCordoned = false
+	nm.Status.Phase = nodemaintenancev1beta1.MaintenanceEnded
+	nm.Status.EndTime = &now
+	nm.Status.LastError = ""
+	nm.Status.PendingPods = nil
+	nm.Status.PendingPodsCount = 0
+	nm.Status.PodEvictions = nil
+	nm.Status.FailedPodsCount = 0
+	nm.Status.LeaseExpiryTime = nil
+	if err := r.updateStatus(nm); err != nil {
+		r.logger.Error(err, "Failed to update NodeMaintenance with \"Ended\" status")
+		return r.onReconcileError(nm, err)
+	}
+	return reconcile.Result{}, nil
+}
+
+// isMaintenanceStarted returns true if the NodeMaintenance cordoned its node, and didn't release it yet.
+// This includes maintenances waiting for the lease of the node after it was taken over by another component.
+// Maintenances of former operator versions don't record whether they cordoned the node, for these the phase is used:
+// a maintenance waiting for a foreign lease didn't touch the node, which is owned by the lease holder,
+// and a maintenance rolled back after the drain timeout already took the node out of maintenance.
+func isMaintenanceStarted(nm *nodemaintenancev1beta1.NodeMaintenance) bool {
+	if nm.Status.NodeCordoned {
+		return true
+	}
+	return nm.Status.Phase != "" && nm.Status.Phase != nodemaintenancev1beta1.MaintenanceEnded &&
+		nm.Status.Phase != nodemaintenancev1beta1.MaintenanceWaitingForLease && !nm.Status.RolledBack
+}
+
+// resetMaintenanceStatus resets the status of an ended NodeMaintenance, in order to start the maintenance again.
+// The phase timeline is kept.
+func resetMaintenanceStatus(nm *nodemaintenancev1beta1.NodeMaintenance) {
+	nm.Status = nodemaintenancev1beta1.NodeMaintenanceStatus{
+		PhaseTransitions: nm.Status.PhaseTransitions,
+	}
+}
+
+// releaseNodeMaintenance stops the maintenance of the node of the given NodeMaintenance,
+// unless another active NodeMaintenance still holds the node in maintenance
+func (r *NodeMaintenanceReconciler) releaseNodeMaintenance(nm *nodemaintenancev1beta1.NodeMaintenance) error {
+	held, err := r.isNodeHeldByOtherMaintenance(nm)
+	if err != nil {
+		return err
+	}
+	if held {
+		r.logger.Info("Node is still held by another NodeMaintenance, keeping it in maintenance", "node", nm.Spec.NodeName)
+		return nil
+	}
+	return r.stopNodeMaintenanceOnDeletion(nm.Spec.NodeName)
+}
+
+// isNodeHeldByOtherMaintenance returns true if another active and not deleted NodeMaintenance exists for the node of the given NodeMaintenance
+func (r *NodeMaintenanceReconciler) isNodeHeldByOtherMaintenance(nm *nodemaintenancev1beta1.NodeMaintenance) (bool, error) {
+	nodeMaintenances := &nodemaintenancev1beta1.NodeMaintenanceList{}
+	if err := r.Client.List(context.TODO(), nodeMaintenances, client.MatchingFields{nodemaintenancev1beta1.NodeNameField: nm.Spec.NodeName}); err != nil {
+		return false, fmt.Errorf("failed to list NodeMaintenances: %v", err)
+	}
+	for _, other := range nodeMaintenances.Items {
+		if other.Spec.NodeName == nm.Spec.NodeName && other.Name != nm.Name &&
+			other.Spec.IsActive() && other.DeletionTimestamp.IsZero() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (r *NodeMaintenanceReconciler) fetchNode(nodeName string) (*corev1.Node, error) {
-	node, err := r.drainer.Client.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+	node := &corev1.Node{}
+	err := r.Client.Get(context.TODO(), client.ObjectKey{Name: nodeName}, node)
 	if err != nil && errors.IsNotFound(err) {
 		r.logger.Error(err, "Node cannot be found", "nodeName", nodeName)
 		return nil, err
@@ -385,44 +637,99 @@ func (r *NodeMaintenanceReconciler) fetchNode(nodeName string) (*corev1.Node, er
 	return node, nil
 }
 
+// initMaintenanceStatus initializes the status of a new maintenance with the pods of the node before the first drain attempt
 func (r *NodeMaintenanceReconciler) initMaintenanceStatus(nm *nodemaintenancev1beta1.NodeMaintenance) error {
 	if nm.Status.Phase == "" {
 		nm.Status.Phase = nodemaintenancev1beta1.MaintenanceRunning
-		pendingList, errlist := r.drainer.GetPodsForDeletion(nm.Spec.NodeName)
-		if errlist != nil {
-			return fmt.Errorf("Failed to get pods for eviction while initializing status")
+		now := metav1.Now()
+		nm.Status.StartTime = &now
+		pendingList, err := r.getPodsForDeletion(nm.Spec.NodeName)
+		if err != nil {
+			return fmt.Errorf("Failed to get pods for eviction while initializing status: %v", err)
 		}
+		var pendingPods []string
 		if pendingList != nil {
-			nm.Status.PendingPods = GetPodNameList(pendingList.Pods())
+			pendingPods = GetPodNameList(pendingList.Pods())
 		}
-		nm.Status.EvictionPods = len(nm.Status.PendingPods)
+		if err := r.setPendingPods(nm, pendingPods); err != nil {
+			return err
+		}
+		nm.Status.EvictionPods = len(pendingPods)
 
-		podlist, err := r.drainer.Client.CoreV1().Pods(metav1.NamespaceAll).List(
-			context.Background(),
-			metav1.ListOptions{
-				FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": nm.Spec.NodeName}).String(),
-			})
-		if err != nil {
+		podlist := &corev1.PodList{}
+		if err := r.Client.List(context.TODO(), podlist, client.MatchingFields{nodemaintenancev1beta1.NodeNameField: nm.Spec.NodeName}); err != nil {
 			return err
 		}
 		nm.Status.TotalPods = len(podlist.Items)
-		err = r.Client.Status().Update(context.TODO(), nm)
+		if skipped := nm.Status.TotalPods - nm.Status.EvictionPods; skipped > 0 {
+			// the cache might not know all pending pods yet
+			nm.Status.SkippedPodsCount = skipped
+		}
+		return r.updateStatus(nm)
+	}
+	return nil
+}
+
+// getPodsForDeletion lists the pods of the node which are deleted by the drain, without the cache.
+// Since every drain attempt evicts pods, the pods are listed at most once before and once after the drain attempt of a reconcile.
+func (r *NodeMaintenanceReconciler) getPodsForDeletion(nodeName string) (*drain.PodDeleteList, error) {
+	pendingList, errs := r.drainer.GetPodsForDeletion(nodeName)
+	if errs != nil {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return pendingList, nil
+}
+
+// updateStatus updates the status of the NodeMaintenance with the current time as last reconcile time,
+// and records a phase transition if the phase changed since the last update.
+// The update is skipped if nothing but the last reconcile time would change, and it was updated less than
+// LastReconcileTimeInterval ago.
+func (r *NodeMaintenanceReconciler) updateStatus(nm *nodemaintenancev1beta1.NodeMaintenance) error {
+	now := metav1.Now()
+	if r.isStatusUnchanged(nm, now.Time) {
+		return nil
+	}
+	nm.Status.LastReconcileTime = &now
+	nm.Status.PhaseTransitions = AppendPhaseTransition(nm.Status.PhaseTransitions, nm.Status.Phase, now, MaxPhaseTransitions)
+	if err := r.Client.Status().Update(context.TODO(), nm); err != nil {
 		return err
 	}
+	r.lastStatus = nm.Status.DeepCopy()
 	return nil
 }
 
+// isStatusUnchanged returns true if the status of the NodeMaintenance equals the last known status,
+// apart from a last reconcile time which is younger than LastReconcileTimeInterval
+func (r *NodeMaintenanceReconciler) isStatusUnchanged(nm *nodemaintenancev1beta1.NodeMaintenance, now time.Time) bool {
+	last := r.lastStatus
+	if last == nil || last.LastReconcileTime == nil || now.Sub(last.LastReconcileTime.Time) >= LastReconcileTimeInterval {
+		return false
+	}
+	status := nm.Status.DeepCopy()
+	status.LastReconcileTime = last.LastReconcileTime
+	return equality.Semantic.DeepEqual(status, last)
+}
+
 func (r *NodeMaintenanceReconciler) onReconcileErrorWithRequeue(nm *nodemaintenancev1beta1.NodeMaintenance, err error, duration *time.Duration) (reconcile.Result, error) {
+	var pendingList *drain.PodDeleteList
+	if nm.Spec.NodeName != "" {
+		pendingList, _ = r.drainer.GetPodsForDeletion(nm.Spec.NodeName)
+	}
+	return r.onDrainErrorWithRequeue(nm, err, duration, pendingList)
+}
+
+// onDrainErrorWithRequeue is onReconcileErrorWithRequeue with the pods of the node which are still pending,
+// for errors after a drain attempt, which listed these pods already. The pending pods aren't updated without list.
+func (r *NodeMaintenanceReconciler) onDrainErrorWithRequeue(nm *nodemaintenancev1beta1.NodeMaintenance, err error, duration *time.Duration, pendingList *drain.PodDeleteList) (reconcile.Result, error) {
 	nm.Status.LastError = err.Error()
 
-	if nm.Spec.NodeName != "" {
-		pendingList, _ := r.drainer.GetPodsForDeletion(nm.Spec.NodeName)
-		if pendingList != nil {
-			nm.Status.PendingPods = GetPodNameList(pendingList.Pods())
+	if pendingList != nil {
+		if err := r.setPendingPods(nm, GetPodNameList(pendingList.Pods())); err != nil {
+			r.logger.Error(err, "Failed to update pod lists", "nodeName", nm.Spec.NodeName)
 		}
 	}
 
-	updateErr := r.Client.Status().Update(context.TODO(), nm)
+	updateErr := r.updateStatus(nm)
 	if updateErr != nil {
 		r.logger.Error(updateErr, "Failed to update NodeMaintenance with \"Failed\" status")
 	}
@@ -3,17 +3,24 @@ package controllers
 import (
 	"context"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	coordv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kubectl/pkg/drain"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
@@ -65,7 +72,7 @@ var _ = Describe("NodeMaintenance", func() {
 		// Create a ReconcileNodeMaintenance object with the scheme and fake client
 		// TODO add reconciler to manager in suite_test.go and don't call reconcile funcs manually
 		r = &NodeMaintenanceReconciler{
-			Client: k8sClient,
+			Client: nodeNameIndexClient{k8sClient},
 			Scheme: scheme.Scheme,
 			logger: ctrl.Log.WithName("unit test"),
 		}
@@ -117,6 +124,10 @@ var _ = Describe("NodeMaintenance", func() {
 			Expect(len(maintenance.Status.PendingPods)).To(Equal(2))
 			Expect(maintenance.Status.EvictionPods).To(Equal(2))
 			Expect(maintenance.Status.TotalPods).To(Equal(2))
+			Expect(maintenance.Status.StartTime).NotTo(BeNil())
+			Expect(maintenance.Status.LastReconcileTime).NotTo(BeNil())
+			Expect(maintenance.Status.PhaseTransitions).To(HaveLen(1))
+			Expect(maintenance.Status.PhaseTransitions[0].Phase).To(Equal(nodemaintenanceapi.MaintenanceRunning))
 		})
 
 		It("owner ref should be set properly", func() {
@@ -197,6 +208,23 @@ var _ = Describe("NodeMaintenance", func() {
 			checkSuccesfulReconcile()
 		})
 
+		It("should reconcile and record the maintenance timeline", func() {
+			reconcileMaintenance(nm)
+			checkSuccesfulReconcile()
+			maintenance := &nodemaintenanceapi.NodeMaintenance{}
+			err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nm), maintenance)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(maintenance.Status.StartTime).NotTo(BeNil())
+			Expect(maintenance.Status.DrainCompletedTime).NotTo(BeNil())
+			Expect(maintenance.Status.DrainCompletedTime.Before(maintenance.Status.StartTime)).To(BeFalse())
+			Expect(maintenance.Status.EndTime).To(BeNil())
+			phases := []nodemaintenanceapi.MaintenancePhase{}
+			for _, transition := range maintenance.Status.PhaseTransitions {
+				phases = append(phases, transition.Phase)
+			}
+			Expect(phases).To(Equal([]nodemaintenanceapi.MaintenancePhase{nodemaintenanceapi.MaintenanceRunning, nodemaintenanceapi.MaintenanceSucceeded}))
+		})
+
 		It("should reconcile and cordon node", func() {
 			reconcileMaintenance(nm)
 			checkSuccesfulReconcile()
@@ -215,6 +243,99 @@ var _ = Describe("NodeMaintenance", func() {
 			Expect(taintExist(node, "kubevirt.io/drain", corev1.TaintEffectNoSchedule)).To(BeTrue())
 		})
 
+		It("should record the maintenance history when deleted", func() {
+			reconcileMaintenance(nm)
+			checkSuccesfulReconcile()
+
+			err := k8sClient.Delete(context.TODO(), nm)
+			Expect(err).NotTo(HaveOccurred())
+			reconcileMaintenance(nm)
+
+			maintenance := &nodemaintenanceapi.NodeMaintenance{}
+			err = k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nm), maintenance)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			records := &nodemaintenanceapi.NodeMaintenanceRecordList{}
+			err = k8sClient.List(context.TODO(), records, client.MatchingLabels{nodemaintenanceapi.RecordNodeMaintenanceLabel: nm.Name})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records.Items).To(HaveLen(1))
+			Expect(records.Items[0].Spec.NodeName).To(Equal(nm.Spec.NodeName))
+			Expect(records.Items[0].Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceSucceeded))
+			Expect(records.Items[0].Status.EvictedPods).To(ConsistOf("test/test-pod-1", "test/test-pod-2"))
+		})
+
+		It("should end and restart maintenance when toggling state", func() {
+			reconcileMaintenance(nm)
+			checkSuccesfulReconcile()
+
+			setState := func(state nodemaintenanceapi.MaintenanceState) {
+				maintenance := &nodemaintenanceapi.NodeMaintenance{}
+				err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nm), maintenance)
+				Expect(err).NotTo(HaveOccurred())
+				maintenance.Spec.State = state
+				err = k8sClient.Update(context.TODO(), maintenance)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			checkNode := func(inMaintenance bool) {
+				node := &corev1.Node{}
+				err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: nm.Spec.NodeName}, node)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(node.Spec.Unschedulable).To(Equal(inMaintenance))
+				Expect(taintExist(node, "kubevirt.io/drain", corev1.TaintEffectNoSchedule)).To(Equal(inMaintenance))
+			}
+			checkNode(true)
+
+			setState(nodemaintenanceapi.MaintenanceInactive)
+			reconcileMaintenance(nm)
+			maintenance := &nodemaintenanceapi.NodeMaintenance{}
+			err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nm), maintenance)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(maintenance.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceEnded))
+			Expect(maintenance.Status.EndTime).NotTo(BeNil())
+			checkNode(false)
+
+			setState(nodemaintenanceapi.MaintenanceActive)
+			reconcileMaintenance(nm)
+			checkSuccesfulReconcile()
+			err = k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nm), maintenance)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(maintenance.Status.EndTime).To(BeNil())
+			Expect(len(maintenance.Status.PhaseTransitions)).To(Equal(5))
+			checkNode(true)
+		})
+
+		It("should keep node in maintenance until the last NodeMaintenance is deleted", func() {
+			checkNodeCordoned := func(cordoned bool) {
+				node := &corev1.Node{}
+				err := k8sClient.Get(context.TODO(), client.ObjectKey{Name: nm.Spec.NodeName}, node)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(node.Spec.Unschedulable).To(Equal(cordoned))
+				Expect(taintExist(node, "kubevirt.io/drain", corev1.TaintEffectNoSchedule)).To(Equal(cordoned))
+			}
+
+			nm2 := getTestNM()
+			nm2.Name = "node-maintanance-2"
+			err := k8sClient.Create(context.TODO(), nm2)
+			Expect(err).NotTo(HaveOccurred())
+			req2 := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nm2)}
+
+			reconcileMaintenance(nm)
+			r.Reconcile(context.Background(), req2)
+			checkNodeCordoned(true)
+
+			err = k8sClient.Delete(context.TODO(), nm)
+			Expect(err).NotTo(HaveOccurred())
+			reconcileMaintenance(nm)
+			err = k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nm), &nodemaintenanceapi.NodeMaintenance{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			checkNodeCordoned(true)
+
+			err = k8sClient.Delete(context.TODO(), nm2)
+			Expect(err).NotTo(HaveOccurred())
+			r.Reconcile(context.Background(), req2)
+			checkNodeCordoned(false)
+		})
+
 		It("should fail on non existing node", func() {
 			nmFail := getTestNM()
 			nmFail.Spec.NodeName = "non-existing"
@@ -229,6 +350,59 @@ var _ = Describe("NodeMaintenance", func() {
 	})
 })
 
+var _ = Describe("NodeMaintenance reference counting", func() {
+
+	var r *NodeMaintenanceReconciler
+	var nm *nodemaintenanceapi.NodeMaintenance
+
+	newReconciler := func(objs ...client.Object) {
+		testScheme := runtime.NewScheme()
+		Expect(nodemaintenanceapi.AddToScheme(testScheme)).To(Succeed())
+		r = &NodeMaintenanceReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(objs...).Build(),
+			Scheme: testScheme,
+			logger: ctrl.Log.WithName("unit test"),
+		}
+	}
+
+	getOtherNM := func(name string) *nodemaintenanceapi.NodeMaintenance {
+		other := getTestNM()
+		other.Name = name
+		return other
+	}
+
+	BeforeEach(func() {
+		nm = getTestNM()
+	})
+
+	It("should not be held without other NodeMaintenances", func() {
+		newReconciler(nm)
+		Expect(r.isNodeHeldByOtherMaintenance(nm)).To(BeFalse())
+	})
+
+	It("should be held by another active NodeMaintenance for the same node", func() {
+		newReconciler(nm, getOtherNM("other"))
+		Expect(r.isNodeHeldByOtherMaintenance(nm)).To(BeTrue())
+	})
+
+	It("should not be held by NodeMaintenances for other nodes", func() {
+		other := getOtherNM("other")
+		other.Spec.NodeName = "node02"
+		newReconciler(nm, other)
+		Expect(r.isNodeHeldByOtherMaintenance(nm)).To(BeFalse())
+	})
+
+	It("should not be held by inactive or deleted NodeMaintenances", func() {
+		inactive := getOtherNM("inactive")
+		inactive.Spec.State = nodemaintenanceapi.MaintenanceInactive
+		deleted := getOtherNM("deleted")
+		deleted.Finalizers = []string{nodemaintenanceapi.NodeMaintenanceFinalizer}
+		deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		newReconciler(nm, inactive, deleted)
+		Expect(r.isNodeHeldByOtherMaintenance(nm)).To(BeFalse())
+	})
+})
+
 func getTestObjects() (*nodemaintenanceapi.NodeMaintenance, []client.Object) {
 	nm := getTestNM()
 
@@ -311,3 +485,257 @@ func getTestNM() *nodemaintenanceapi.NodeMaintenance {
 		},
 	}
 }
+
+var _ = Describe("NodeMaintenance lease renewal", func() {
+
+	var r *NodeMaintenanceReconciler
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		node = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node01"}}
+		cl := fake.NewClientBuilder().WithObjects(node).Build()
+		r = &NodeMaintenanceReconciler{
+			Client:           cl,
+			isLeaseSupported: true,
+			leaseManager:     newLeaseManager(cl, LeaseNamespaceDefault),
+			logger:           ctrl.Log.WithName("unit test"),
+		}
+	})
+
+	It("should report the lease expiry time", func() {
+		r.isLeaseSupported = false
+		expiryTime, _, err := r.obtainLease(node)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expiryTime).To(BeNil())
+
+		r.isLeaseSupported = true
+		expiryTime, _, err = r.obtainLease(node)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expiryTime.Time).To(BeTemporally("~", time.Now().Add(LeaseDuration), time.Minute))
+	})
+
+	It("should renew the lease before it expires", func() {
+		Expect(leaseRenewalDelay(nil)).To(BeZero())
+
+		expiryTime := metav1.NewTime(time.Now().Add(LeaseDuration))
+		Expect(leaseRenewalDelay(&expiryTime)).To(BeNumerically("~", LeaseDuration-DrainerTimeout, time.Second))
+
+		expiryTime = metav1.NewTime(time.Now().Add(-time.Minute))
+		Expect(leaseRenewalDelay(&expiryTime)).To(Equal(time.Second))
+	})
+
+	It("should renew the lease when reconciled after the renewal delay", func() {
+		_, _, err := r.obtainLease(node)
+		Expect(err).NotTo(HaveOccurred())
+
+		// move the lease to the time of the requeue
+		lease := &coordv1.Lease{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKey{Namespace: LeaseNamespaceDefault, Name: node.Name}, lease)).To(Succeed())
+		lease.Spec.RenewTime = &metav1.MicroTime{Time: time.Now().Add(-LeaseDuration + DrainerTimeout)}
+		Expect(r.Client.Update(context.TODO(), lease)).To(Succeed())
+
+		expiryTime, _, err := r.obtainLease(node)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expiryTime.Time).To(BeTemporally("~", time.Now().Add(LeaseDuration), time.Minute))
+	})
+})
+
+var _ = Describe("NodeMaintenance waiting for lease", func() {
+
+	var r *NodeMaintenanceReconciler
+	var nm *nodemaintenanceapi.NodeMaintenance
+	var foreignLease *coordv1.Lease
+
+	BeforeEach(func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node01"}}
+		nm = getTestNM()
+		acquireTime := metav1.NowMicro()
+		foreignLease = &coordv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: node.Name, Namespace: LeaseNamespaceDefault},
+			Spec: coordv1.LeaseSpec{
+				HolderIdentity:       pointer.String("remediation"),
+				LeaseDurationSeconds: pointer.Int32(600),
+				AcquireTime:          &acquireTime,
+				RenewTime:            &acquireTime,
+			},
+		}
+
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(nodemaintenanceapi.AddToScheme(testScheme)).To(Succeed())
+		cl := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(node, nm, foreignLease).Build()
+		r = &NodeMaintenanceReconciler{
+			Client:           cl,
+			Scheme:           testScheme,
+			isLeaseSupported: true,
+			leaseManager:     newLeaseManager(cl, LeaseNamespaceDefault),
+			drainer:          &drain.Helper{Client: k8sfake.NewSimpleClientset(node), Ctx: context.Background()},
+		}
+	})
+
+	It("should record the foreign lease and requeue at its expiry", func() {
+		res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nm)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.RequeueAfter).To(BeNumerically("~", 601*time.Second, 2*time.Second))
+
+		maintenance := &nodemaintenanceapi.NodeMaintenance{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKeyFromObject(nm), maintenance)).To(Succeed())
+		Expect(maintenance.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceWaitingForLease))
+		Expect(maintenance.Status.LastError).NotTo(BeEmpty())
+		Expect(maintenance.Status.ForeignLease).NotTo(BeNil())
+		Expect(maintenance.Status.ForeignLease.HolderIdentity).To(Equal("remediation"))
+		Expect(maintenance.Status.ForeignLease.AcquireTime.Unix()).To(Equal(foreignLease.Spec.AcquireTime.Unix()))
+		Expect(maintenance.Status.ForeignLease.ExpiryTime.Time).To(BeTemporally("~", time.Now().Add(600*time.Second), 2*time.Second))
+
+		node := &corev1.Node{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKey{Name: nm.Spec.NodeName}, node)).To(Succeed())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+	})
+
+	It("should not consider the maintenance as started", func() {
+		nm.Status.Phase = nodemaintenanceapi.MaintenanceWaitingForLease
+		Expect(isMaintenanceStarted(nm)).To(BeFalse())
+	})
+
+	It("should uncordon the node on deletion after the lease was taken over", func() {
+		// start the maintenance without foreign lease
+		Expect(r.Client.Delete(context.TODO(), foreignLease)).To(Succeed())
+		req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nm)}
+		_, err := r.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+
+		maintenance := &nodemaintenanceapi.NodeMaintenance{}
+		Expect(r.Client.Get(context.TODO(), req.NamespacedName, maintenance)).To(Succeed())
+		Expect(maintenance.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceSucceeded))
+		Expect(maintenance.Status.NodeCordoned).To(BeTrue())
+		node, err := r.drainer.Client.CoreV1().Nodes().Get(context.TODO(), nm.Spec.NodeName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(node.Spec.Unschedulable).To(BeTrue())
+		// the drainer has its own fake client, mirror the cordoned node to the client of the reconciler
+		node.ResourceVersion = ""
+		Expect(r.Client.Update(context.TODO(), node)).To(Succeed())
+
+		// another component takes over the lease
+		lease := &coordv1.Lease{}
+		Expect(r.Client.Get(context.TODO(), client.ObjectKey{Namespace: LeaseNamespaceDefault, Name: nm.Spec.NodeName}, lease)).To(Succeed())
+		now := metav1.NowMicro()
+		lease.Spec.HolderIdentity = pointer.String("remediation")
+		lease.Spec.AcquireTime = &now
+		lease.Spec.RenewTime = &now
+		Expect(r.Client.Update(context.TODO(), lease)).To(Succeed())
+
+		_, err = r.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Client.Get(context.TODO(), req.NamespacedName, maintenance)).To(Succeed())
+		Expect(maintenance.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceWaitingForLease))
+		Expect(isMaintenanceStarted(maintenance)).To(BeTrue())
+
+		Expect(r.Client.Delete(context.TODO(), maintenance)).To(Succeed())
+		_, err = r.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(r.Client.Get(context.TODO(), req.NamespacedName, maintenance))).To(BeTrue())
+
+		node, err = r.drainer.Client.CoreV1().Nodes().Get(context.TODO(), nm.Spec.NodeName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+		Expect(node.Spec.Taints).To(BeEmpty())
+
+		// the foreign lease is kept
+		Expect(r.Client.Get(context.TODO(), client.ObjectKey{Namespace: LeaseNamespaceDefault, Name: nm.Spec.NodeName}, lease)).To(Succeed())
+		Expect(*lease.Spec.HolderIdentity).To(Equal("remediation"))
+	})
+})
+
+var _ = Describe("NodeMaintenance in the operator namespace", func() {
+
+	const operatorNamespace = "operator-namespace"
+
+	It("should keep the lease and the pod lists in the namespace of the reconciler", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node01"}}
+		nm := getTestNM()
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(nodemaintenanceapi.AddToScheme(testScheme)).To(Succeed())
+		cl := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(node, nm).Build()
+		clientset := k8sfake.NewSimpleClientset(node)
+		r := &NodeMaintenanceReconciler{
+			Client:           cl,
+			Scheme:           testScheme,
+			SpillPodLists:    true,
+			LeaseNamespace:   operatorNamespace,
+			isLeaseSupported: true,
+			leaseManager:     newLeaseManager(cl, operatorNamespace),
+			drainer:          &drain.Helper{Client: clientset, Ctx: context.Background()},
+		}
+
+		req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(nm)}
+		_, err := r.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cl.Get(context.TODO(), client.ObjectKey{Namespace: operatorNamespace, Name: node.Name}, &coordv1.Lease{})).To(Succeed())
+		err = cl.Get(context.TODO(), client.ObjectKey{Namespace: LeaseNamespaceDefault, Name: node.Name}, &coordv1.Lease{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		maintenance := &nodemaintenanceapi.NodeMaintenance{}
+		Expect(cl.Get(context.TODO(), req.NamespacedName, maintenance)).To(Succeed())
+		Expect(maintenance.Status.PodListConfigMap).NotTo(BeNil())
+		Expect(maintenance.Status.PodListConfigMap.Namespace).To(Equal(operatorNamespace))
+		_, err = clientset.CoreV1().ConfigMaps(operatorNamespace).Get(context.TODO(), maintenance.Status.PodListConfigMap.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("NodeMaintenance status update", func() {
+
+	var cl client.Client
+	var r *NodeMaintenanceReconciler
+	var nm *nodemaintenanceapi.NodeMaintenance
+
+	BeforeEach(func() {
+		nm = getTestNM()
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(nodemaintenanceapi.AddToScheme(testScheme)).To(Succeed())
+		cl = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(nm).Build()
+		r = &NodeMaintenanceReconciler{Client: cl, Scheme: testScheme}
+		Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(nm), nm)).To(Succeed())
+		r.lastStatus = nm.Status.DeepCopy()
+	})
+
+	getResourceVersion := func() string {
+		stored := &nodemaintenanceapi.NodeMaintenance{}
+		Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(nm), stored)).To(Succeed())
+		return stored.ResourceVersion
+	}
+
+	It("should only update the status if it changed", func() {
+		nm.Status.Phase = nodemaintenanceapi.MaintenanceRunning
+		Expect(r.updateStatus(nm)).To(Succeed())
+		Expect(nm.Status.PhaseTransitions).To(HaveLen(1))
+		lastReconcileTime := nm.Status.LastReconcileTime
+		resourceVersion := getResourceVersion()
+
+		Expect(r.updateStatus(nm)).To(Succeed())
+		Expect(getResourceVersion()).To(Equal(resourceVersion))
+		Expect(nm.Status.LastReconcileTime).To(Equal(lastReconcileTime))
+
+		nm.Status.LastError = "some pods pending"
+		Expect(r.updateStatus(nm)).To(Succeed())
+		Expect(getResourceVersion()).NotTo(Equal(resourceVersion))
+		Expect(nm.Status.PhaseTransitions).To(HaveLen(1))
+	})
+
+	It("should update the last reconcile time after the interval", func() {
+		nm.Status.Phase = nodemaintenanceapi.MaintenanceRunning
+		Expect(r.updateStatus(nm)).To(Succeed())
+		lastReconcileTime := metav1.NewTime(time.Now().Add(-LastReconcileTimeInterval))
+		nm.Status.LastReconcileTime = &lastReconcileTime
+		r.lastStatus.LastReconcileTime = &lastReconcileTime
+		resourceVersion := getResourceVersion()
+
+		Expect(r.updateStatus(nm)).To(Succeed())
+		Expect(getResourceVersion()).NotTo(Equal(resourceVersion))
+		Expect(nm.Status.LastReconcileTime.Time).To(BeTemporally(">", lastReconcileTime.Time))
+		Expect(nm.Status.PhaseTransitions).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,82 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+const (
+	// DefaultRecordRetention is the default time NodeMaintenanceRecords are kept
+	DefaultRecordRetention = 30 * 24 * time.Hour
+)
+
+// NodeMaintenanceRecordReconciler deletes NodeMaintenanceRecords after their retention time
+type NodeMaintenanceRecordReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Retention is the time NodeMaintenanceRecords are kept, zero means forever
+	Retention time.Duration
+}
+
+//+kubebuilder:rbac:groups=nodemaintenance.kubevirt.io,resources=nodemaintenancerecords,verbs=get;list;watch;create;delete
+
+// Reconcile deletes the NodeMaintenanceRecord when its retention time is over,
+// and requeues it for that time otherwise.
+func (r *NodeMaintenanceRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if r.Retention == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	record := &nodemaintenancev1beta1.NodeMaintenanceRecord{}
+	if err := r.Client.Get(ctx, req.NamespacedName, record); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	expiry := record.CreationTimestamp.Add(r.Retention)
+	if remaining := time.Until(expiry); remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+
+	logger.Info("Deleting expired NodeMaintenanceRecord", "retention", r.Retention.String())
+	if err := r.Client.Delete(ctx, record); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodeMaintenanceRecordReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&nodemaintenancev1beta1.NodeMaintenanceRecord{}).
+		Complete(r)
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// failingRecordClient fails creating NodeMaintenanceRecords with the given error
+type failingRecordClient struct {
+	client.Client
+	err error
+}
+
+func (c *failingRecordClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*nodemaintenanceapi.NodeMaintenanceRecord); ok {
+		return c.err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+var _ = Describe("NodeMaintenanceRecord", func() {
+
+	var testScheme *runtime.Scheme
+
+	BeforeEach(func() {
+		testScheme = runtime.NewScheme()
+		Expect(nodemaintenanceapi.AddToScheme(testScheme)).To(Succeed())
+	})
+
+	Context("creation", func() {
+
+		var nm *nodemaintenanceapi.NodeMaintenance
+
+		BeforeEach(func() {
+			nm = getTestNM()
+			nm.UID = "1234"
+			nm.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+			nm.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			nm.Annotations = map[string]string{
+				nodemaintenanceapi.RequesterAnnotation:       "admin",
+				nodemaintenanceapi.RequesterGroupsAnnotation: "system:masters,system:authenticated",
+			}
+			nm.Status.Phase = nodemaintenanceapi.MaintenanceSucceeded
+			nm.Status.TotalPods = 3
+			nm.Status.EvictionPods = 2
+			nm.Status.EvictedPods = []string{"test-pod-1", "test-pod-2"}
+		})
+
+		It("should record the history of the NodeMaintenance", func() {
+			cl := fake.NewClientBuilder().WithScheme(testScheme).Build()
+			Expect(createMaintenanceRecord(cl, nm)).To(Succeed())
+
+			record := &nodemaintenanceapi.NodeMaintenanceRecord{}
+			Expect(cl.Get(context.TODO(), client.ObjectKey{Name: "node-maintanance-1234"}, record)).To(Succeed())
+			Expect(record.Labels).To(HaveKeyWithValue(nodemaintenanceapi.RecordNodeNameLabel, "node01"))
+			Expect(record.Labels).To(HaveKeyWithValue(nodemaintenanceapi.RecordNodeMaintenanceLabel, nm.Name))
+			Expect(record.Spec).To(Equal(nm.Spec))
+			Expect(record.Status.NodeMaintenanceUID).To(Equal(nm.UID))
+			Expect(record.Status.Requester).To(Equal("admin"))
+			Expect(record.Status.RequesterGroups).To(Equal([]string{"system:masters", "system:authenticated"}))
+			Expect(record.Status.StartTime.Unix()).To(Equal(nm.CreationTimestamp.Unix()))
+			Expect(record.Status.EndTime.Unix()).To(Equal(nm.DeletionTimestamp.Unix()))
+			Expect(record.Status.Phase).To(Equal(nodemaintenanceapi.MaintenanceSucceeded))
+			Expect(record.Status.TotalPods).To(Equal(3))
+			Expect(record.Status.EvictionPods).To(Equal(2))
+			Expect(record.Status.EvictedPods).To(ConsistOf("test-pod-1", "test-pod-2"))
+		})
+
+		It("should not fail if the record already exists", func() {
+			cl := fake.NewClientBuilder().WithScheme(testScheme).Build()
+			Expect(createMaintenanceRecord(cl, nm)).To(Succeed())
+			Expect(createMaintenanceRecord(cl, nm)).To(Succeed())
+		})
+
+		It("should shorten long names in the labels", func() {
+			nm.Name = strings.Repeat("n", 100)
+			nm.Spec.NodeName = strings.Repeat("node.", 20)
+			cl := fake.NewClientBuilder().WithScheme(testScheme).Build()
+			Expect(createMaintenanceRecord(cl, nm)).To(Succeed())
+
+			record := &nodemaintenanceapi.NodeMaintenanceRecord{}
+			Expect(cl.Get(context.TODO(), client.ObjectKey{Name: nm.Name + "-1234"}, record)).To(Succeed())
+			for _, value := range record.Labels {
+				Expect(validation.IsValidLabelValue(value)).To(BeEmpty(), value)
+			}
+			Expect(record.Labels).To(HaveKeyWithValue(nodemaintenanceapi.RecordNodeNameLabel, nodemaintenanceapi.RecordLabelValue(nm.Spec.NodeName)))
+			Expect(record.Labels).To(HaveKeyWithValue(nodemaintenanceapi.RecordNodeMaintenanceLabel, nodemaintenanceapi.RecordLabelValue(nm.Name)))
+			Expect(record.Status.NodeMaintenanceName).To(Equal(nm.Name))
+			Expect(record.Spec.NodeName).To(Equal(nm.Spec.NodeName))
+
+			// names with the same prefix get different label values
+			Expect(nodemaintenanceapi.RecordLabelValue(nm.Name + "x")).NotTo(Equal(nodemaintenanceapi.RecordLabelValue(nm.Name)))
+		})
+
+		Context("failing", func() {
+
+			var recorder *record.FakeRecorder
+
+			recordWithError := func(err error) error {
+				recorder = record.NewFakeRecorder(10)
+				r := &NodeMaintenanceReconciler{
+					Client:   &failingRecordClient{Client: fake.NewClientBuilder().WithScheme(testScheme).Build(), err: err},
+					recorder: recorder,
+					logger:   ctrl.Log.WithName("test"),
+				}
+				return r.recordMaintenance(nm)
+			}
+
+			It("should retry failures shortly after the deletion", func() {
+				Expect(recordWithError(errors.NewServiceUnavailable("test"))).NotTo(Succeed())
+				Expect(recorder.Events).To(BeEmpty())
+			})
+
+			It("should give up failures after the retry timeout", func() {
+				nm.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(-recordRetryTimeout)}
+				Expect(recordWithError(errors.NewServiceUnavailable("test"))).To(Succeed())
+				Expect(<-recorder.Events).To(ContainSubstring(EventReasonRecordFailed))
+			})
+
+			It("should give up invalid records immediately", func() {
+				invalid := errors.NewInvalid(nodemaintenanceapi.GroupVersion.WithKind("NodeMaintenanceRecord").GroupKind(), "test", nil)
+				Expect(recordWithError(invalid)).To(Succeed())
+				Expect(<-recorder.Events).To(ContainSubstring(EventReasonRecordFailed))
+			})
+		})
+	})
+
+	Context("retention", func() {
+
+		getRecord := func(age time.Duration) *nodemaintenanceapi.NodeMaintenanceRecord {
+			return &nodemaintenanceapi.NodeMaintenanceRecord{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-record",
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+				},
+			}
+		}
+
+		reconcileRecord := func(record *nodemaintenanceapi.NodeMaintenanceRecord, retention time.Duration) (client.Client, ctrl.Result) {
+			cl := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(record).Build()
+			r := &NodeMaintenanceRecordReconciler{
+				Client:    cl,
+				Scheme:    testScheme,
+				Retention: retention,
+			}
+			res, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: record.Name}})
+			Expect(err).ToNot(HaveOccurred())
+			return cl, res
+		}
+
+		It("should keep and requeue records within retention time", func() {
+			record := getRecord(time.Hour)
+			cl, res := reconcileRecord(record, 2*time.Hour)
+			Expect(res.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+			Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(record), record)).To(Succeed())
+		})
+
+		It("should delete records after retention time", func() {
+			record := getRecord(3 * time.Hour)
+			cl, res := reconcileRecord(record, 2*time.Hour)
+			Expect(res.RequeueAfter).To(BeZero())
+			err := cl.Get(context.TODO(), client.ObjectKeyFromObject(record), record)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep records forever without retention time", func() {
+			record := getRecord(24 * 365 * time.Hour)
+			cl, res := reconcileRecord(record, 0)
+			Expect(res.RequeueAfter).To(BeZero())
+			Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(record), record)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,210 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/drain"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+	"kubevirt.io/node-maintenance-operator/pkg/lease"
+)
+
+const (
+	// DefaultOrphanScanInterval is the default interval of scanning for orphaned maintenances
+	DefaultOrphanScanInterval = 10 * time.Minute
+
+	// EventReasonOrphanedMaintenance is the reason of the event emitted for nodes with orphaned maintenance state
+	EventReasonOrphanedMaintenance = "OrphanedMaintenance"
+	// EventReasonOrphanedMaintenanceCleanedUp is the reason of the event emitted when orphaned maintenance state was removed
+	EventReasonOrphanedMaintenanceCleanedUp = "OrphanedMaintenanceCleanedUp"
+)
+
+var (
+	orphanedNodesGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "node_maintenance_orphaned_nodes",
+		Help: "Number of nodes with maintenance taints or a node maintenance lease, but without NodeMaintenance",
+	})
+	orphanCleanupsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "node_maintenance_orphan_cleanups_total",
+		Help: "Number of nodes whose orphaned maintenance state was removed",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(orphanedNodesGauge, orphanCleanupsCounter)
+}
+
+// OrphanScanner periodically looks for nodes which are in maintenance without a NodeMaintenance referring to them,
+// e.g. because a NodeMaintenance was force deleted or the operator was uninstalled during a maintenance.
+// A node is in maintenance when it has the kubevirt.io/drain taint, or when the operator holds its lease.
+// Orphaned nodes are reported with events and metrics, and are optionally taken out of maintenance.
+type OrphanScanner struct {
+	client.Client
+	// Interval is the interval of the scan, the first scan runs at startup
+	Interval time.Duration
+	// Cleanup enables removing the taints, uncordoning and releasing the lease of orphaned nodes
+	Cleanup bool
+	// LeaseNamespace is the namespace of the node leases
+	LeaseNamespace string
+
+	clientset    kubernetes.Interface
+	recorder     record.EventRecorder
+	leaseManager lease.Manager
+}
+
+var _ manager.Runnable = &OrphanScanner{}
+var _ manager.LeaderElectionRunnable = &OrphanScanner{}
+
+// SetupWithManager adds the scanner to the Manager
+func (s *OrphanScanner) SetupWithManager(mgr ctrl.Manager) error {
+	cs, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	s.clientset = cs
+	s.recorder = mgr.GetEventRecorderFor("node-maintenance-orphan-scanner")
+	s.leaseManager = newLeaseManager(s.Client, s.LeaseNamespace)
+	return mgr.Add(s)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only the leader scans
+func (s *OrphanScanner) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable, it scans until the context is done
+func (s *OrphanScanner) Start(ctx context.Context) error {
+	logger := ctrl.Log.WithName("orphan-scanner")
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.scan(ctx); err != nil {
+			logger.Error(err, "failed to scan for orphaned maintenances")
+		}
+	}, s.Interval)
+	return nil
+}
+
+// scan reports the orphaned nodes, and cleans them up if enabled
+func (s *OrphanScanner) scan(ctx context.Context) error {
+	logger := ctrl.Log.WithName("orphan-scanner")
+
+	nmList := &nodemaintenancev1beta1.NodeMaintenanceList{}
+	if err := s.List(ctx, nmList); err != nil {
+		return fmt.Errorf("could not list NodeMaintenances: %v", err)
+	}
+	maintainedNodes := map[string]bool{}
+	for _, nm := range nmList.Items {
+		maintainedNodes[nm.Spec.NodeName] = true
+	}
+
+	nodeList := &corev1.NodeList{}
+	if err := s.List(ctx, nodeList); err != nil {
+		return fmt.Errorf("could not list nodes: %v", err)
+	}
+
+	orphanedNodes := 0
+	var errs []string
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if maintainedNodes[node.Name] {
+			continue
+		}
+		tainted := hasDrainTaint(node)
+		leased, err := s.isLeasedByOperator(ctx, node.Name)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if !tainted && !leased {
+			continue
+		}
+
+		orphanedNodes++
+		logger.Info("found orphaned maintenance", "node", node.Name, "tainted", tainted, "leased", leased)
+		s.recorder.Eventf(node, corev1.EventTypeWarning, EventReasonOrphanedMaintenance,
+			"Node %s is in maintenance without NodeMaintenance (drain taint: %t, lease: %t)", node.Name, tainted, leased)
+
+		if !s.Cleanup {
+			continue
+		}
+		if err := s.cleanup(ctx, node, tainted); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		orphanedNodes--
+		orphanCleanupsCounter.Inc()
+		logger.Info("removed orphaned maintenance", "node", node.Name)
+		s.recorder.Eventf(node, corev1.EventTypeNormal, EventReasonOrphanedMaintenanceCleanedUp,
+			"Node %s was taken out of orphaned maintenance", node.Name)
+	}
+	orphanedNodesGauge.Set(float64(orphanedNodes))
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to process orphaned nodes: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (s *OrphanScanner) isLeasedByOperator(ctx context.Context, nodeName string) (bool, error) {
+	status, err := s.leaseManager.Check(ctx, nodeName)
+	if err != nil {
+		return false, fmt.Errorf("could not check lease of node %s: %v", nodeName, err)
+	}
+	return status != nil && status.Valid && status.IsHeldBy(LeaseHolderIdentity), nil
+}
+
+// cleanup removes the maintenance taints, uncordons the node if it was tainted by the operator, and releases the lease
+func (s *OrphanScanner) cleanup(ctx context.Context, node *corev1.Node, tainted bool) error {
+	if tainted {
+		if err := AddOrRemoveTaint(s.clientset, node, false); err != nil {
+			return fmt.Errorf("could not remove taints of node %s: %v", node.Name, err)
+		}
+		drainer := &drain.Helper{Ctx: ctx, Client: s.clientset}
+		if err := drain.RunCordonOrUncordon(drainer, node, false); err != nil {
+			return fmt.Errorf("could not uncordon node %s: %v", node.Name, err)
+		}
+	}
+	if err := s.leaseManager.Release(ctx, node.Name); err != nil {
+		return fmt.Errorf("could not release lease of node %s: %v", node.Name, err)
+	}
+	return nil
+}
+
+// hasDrainTaint returns true if the node has the taint which is only set by the operator.
+// The node.kubernetes.io/unschedulable taint isn't checked, since it is set for every cordoned node.
+func hasDrainTaint(node *corev1.Node) bool {
+	for i := range node.Spec.Taints {
+		if KubevirtDrainTaint.MatchTaint(&node.Spec.Taints[i]) {
+			return true
+		}
+	}
+	return false
+}
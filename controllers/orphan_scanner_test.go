@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+var _ = Describe("Orphan Scanner", func() {
+
+	var cl client.Client
+	var clientset *k8sfake.Clientset
+	var recorder *record.FakeRecorder
+	var scanner *OrphanScanner
+
+	getNode := func(name string, tainted bool) *corev1.Node {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if tainted {
+			node.Spec.Unschedulable = true
+			node.Spec.Taints = append([]corev1.Taint{}, MaintenanceTaints...)
+		}
+		return node
+	}
+
+	setup := func(cleanup bool, objs ...client.Object) {
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(nodemaintenanceapi.AddToScheme(testScheme)).To(Succeed())
+		cl = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(objs...).Build()
+
+		var runtimeObjs []runtime.Object
+		for _, obj := range objs {
+			if node, ok := obj.(*corev1.Node); ok {
+				runtimeObjs = append(runtimeObjs, node.DeepCopy())
+			}
+		}
+		clientset = k8sfake.NewSimpleClientset(runtimeObjs...)
+		recorder = record.NewFakeRecorder(10)
+		scanner = &OrphanScanner{
+			Client:       cl,
+			Interval:     time.Minute,
+			Cleanup:      cleanup,
+			clientset:    clientset,
+			recorder:     recorder,
+			leaseManager: newLeaseManager(cl, LeaseNamespaceDefault),
+		}
+	}
+
+	It("should report tainted nodes without NodeMaintenance", func() {
+		setup(false, getNode("node-orphaned", true), getNode("node-ok", false))
+		Expect(scanner.scan(context.TODO())).To(Succeed())
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(<-recorder.Events).To(ContainSubstring(EventReasonOrphanedMaintenance))
+
+		node, err := clientset.CoreV1().Nodes().Get(context.TODO(), "node-orphaned", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(node.Spec.Unschedulable).To(BeTrue())
+	})
+
+	It("should report nodes with a lease of the operator without NodeMaintenance", func() {
+		node := getNode("node-leased", false)
+		setup(false, node)
+		_, err := scanner.leaseManager.Acquire(context.TODO(), node, LeaseDuration)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scanner.scan(context.TODO())).To(Succeed())
+		Expect(recorder.Events).To(HaveLen(1))
+	})
+
+	It("should ignore nodes with NodeMaintenance", func() {
+		nm := getTestNM()
+		setup(false, getNode(nm.Spec.NodeName, true), nm)
+		Expect(scanner.scan(context.TODO())).To(Succeed())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should clean up orphaned nodes when enabled", func() {
+		node := getNode("node-orphaned", true)
+		setup(true, node)
+		_, err := scanner.leaseManager.Acquire(context.TODO(), node, LeaseDuration)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scanner.scan(context.TODO())).To(Succeed())
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(ContainSubstring(EventReasonOrphanedMaintenance))
+		Expect(<-recorder.Events).To(ContainSubstring(EventReasonOrphanedMaintenanceCleanedUp))
+
+		node, err = clientset.CoreV1().Nodes().Get(context.TODO(), "node-orphaned", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+		Expect(hasDrainTaint(node)).To(BeFalse())
+
+		status, err := scanner.leaseManager.Check(context.TODO(), "node-orphaned")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Valid).To(BeFalse())
+	})
+})
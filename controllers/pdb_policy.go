@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubectl/pkg/drain"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+	"kubevirt.io/node-maintenance-operator/pkg/pdb"
+)
+
+const (
+	// EventReasonPDBBypassed is the reason of the event emitted when pods are deleted without eviction
+	EventReasonPDBBypassed = "PDBBypassed"
+	// EventReasonPDBViolated is the reason of the event emitted for every PodDisruptionBudget violated by deleting a pod
+	EventReasonPDBViolated = "PDBViolated"
+)
+
+// isPDBBypassDue returns true if the bypass deadline of the PDB policy of the maintenance passed
+func isPDBBypassDue(nm *nodemaintenancev1beta1.NodeMaintenance, now time.Time) bool {
+	policy := nm.Spec.PDBPolicy
+	if policy == nil || policy.Type != nodemaintenancev1beta1.PDBPolicyBypassAfter || policy.BypassAfter == nil || nm.Status.StartTime == nil {
+		return false
+	}
+	return !now.Before(nm.Status.StartTime.Add(policy.BypassAfter.Duration))
+}
+
+// recordViolatedPDBs adds the PodDisruptionBudgets, which don't allow the disruption of the given remaining pods of the node,
+// to the violated PDBs of the status, since these pods are about to be deleted without eviction
+func (r *NodeMaintenanceReconciler) recordViolatedPDBs(nm *nodemaintenancev1beta1.NodeMaintenance, podList *drain.PodDeleteList) error {
+	// the bypass is announced once, when it starts, not by every requeue
+	if nm.Status.PDBBypassTime == nil {
+		now := metav1.Now()
+		nm.Status.PDBBypassTime = &now
+		r.recorder.Eventf(nm, corev1.EventTypeWarning, EventReasonPDBBypassed,
+			"Deleting %d remaining pods of node %s without eviction, PodDisruptionBudgets are bypassed", len(podList.Pods()), nm.Spec.NodeName)
+	}
+
+	pdbsByNamespace := map[string][]policyv1.PodDisruptionBudget{}
+	for _, pod := range podList.Pods() {
+		budgets, found := pdbsByNamespace[pod.Namespace]
+		if !found {
+			var err error
+			budgets, err = pdb.List(context.Background(), r.drainer.Client, r.isPolicyV1Supported, pod.Namespace)
+			if err != nil {
+				return fmt.Errorf("failed to list PodDisruptionBudgets: %v", err)
+			}
+			pdbsByNamespace[pod.Namespace] = budgets
+		}
+
+		for _, budget := range budgets {
+			if budget.Status.DisruptionsAllowed > 0 || budget.Spec.Selector == nil {
+				continue
+			}
+			// an empty policy/v1 selector matches all pods
+			selector, err := metav1.LabelSelectorAsSelector(budget.Spec.Selector)
+			if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			pdbName := fmt.Sprintf("%s/%s", budget.Namespace, budget.Name)
+			if ContainsString(nm.Status.ViolatedPDBs, pdbName) {
+				continue
+			}
+			nm.Status.ViolatedPDBs = append(nm.Status.ViolatedPDBs, pdbName)
+			r.logger.Info("Deleting pod violates PodDisruptionBudget", "pod", pod.Name, "namespace", pod.Namespace, "pdb", budget.Name)
+			r.recorder.Eventf(nm, corev1.EventTypeWarning, EventReasonPDBViolated,
+				"Deleting pod %s/%s violates PodDisruptionBudget %s", pod.Namespace, pod.Name, pdbName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/drain"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+var _ = Describe("PDB policy", func() {
+
+	Context("bypass deadline", func() {
+
+		var nm *nodemaintenanceapi.NodeMaintenance
+
+		BeforeEach(func() {
+			nm = getTestNM()
+			startTime := metav1.NewTime(time.Now().Add(-time.Hour))
+			nm.Status.StartTime = &startTime
+		})
+
+		It("should not bypass PDBs by default", func() {
+			Expect(isPDBBypassDue(nm, time.Now())).To(BeFalse())
+
+			nm.Spec.PDBPolicy = &nodemaintenanceapi.PDBPolicy{Type: nodemaintenanceapi.PDBPolicyRespect}
+			Expect(isPDBBypassDue(nm, time.Now())).To(BeFalse())
+		})
+
+		It("should bypass PDBs after the deadline only", func() {
+			nm.Spec.PDBPolicy = &nodemaintenanceapi.PDBPolicy{
+				Type:        nodemaintenanceapi.PDBPolicyBypassAfter,
+				BypassAfter: &metav1.Duration{Duration: 2 * time.Hour},
+			}
+			Expect(isPDBBypassDue(nm, time.Now())).To(BeFalse())
+			Expect(isPDBBypassDue(nm, time.Now().Add(time.Hour))).To(BeTrue())
+		})
+	})
+
+	Context("violated PDBs", func() {
+
+		getPod := func(name string, app string) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"app": app}},
+				Spec:       corev1.PodSpec{NodeName: "node01"},
+			}
+		}
+
+		getPDB := func(name string, app string, disruptionsAllowed int32) *policyv1beta1.PodDisruptionBudget {
+			return &policyv1beta1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+				Spec: policyv1beta1.PodDisruptionBudgetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+				},
+				Status: policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+			}
+		}
+
+		It("should record the PDBs which don't allow disruptions", func() {
+			clientset := k8sfake.NewSimpleClientset(
+				getPod("blocked", "blocked"), getPod("allowed", "allowed"),
+				getPDB("pdb-blocking", "blocked", 0), getPDB("pdb-allowing", "allowed", 1), getPDB("pdb-other", "other", 0),
+			)
+			recorder := record.NewFakeRecorder(10)
+			r := &NodeMaintenanceReconciler{
+				drainer:  &drain.Helper{Client: clientset, Ctx: context.Background(), Force: true},
+				recorder: recorder,
+				logger:   ctrl.Log.WithName("test"),
+			}
+			nm := getTestNM()
+			podList, err := r.getPodsForDeletion(nm.Spec.NodeName)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.recordViolatedPDBs(nm, podList)).To(Succeed())
+			Expect(nm.Status.ViolatedPDBs).To(Equal([]string{"default/pdb-blocking"}))
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(ContainSubstring(EventReasonPDBBypassed))
+			Expect(<-recorder.Events).To(ContainSubstring(EventReasonPDBViolated))
+
+			Expect(r.recordViolatedPDBs(nm, podList)).To(Succeed())
+			Expect(nm.Status.ViolatedPDBs).To(HaveLen(1))
+		})
+
+		It("should announce the bypass once, but every violated PDB", func() {
+			clientset := k8sfake.NewSimpleClientset(getPod("blocked", "blocked"), getPDB("pdb-blocking", "blocked", 0))
+			recorder := record.NewFakeRecorder(10)
+			r := &NodeMaintenanceReconciler{
+				drainer:  &drain.Helper{Client: clientset, Ctx: context.Background(), Force: true},
+				recorder: recorder,
+				logger:   ctrl.Log.WithName("test"),
+			}
+			nm := getTestNM()
+			nm.Spec.PDBPolicy = &nodemaintenanceapi.PDBPolicy{
+				Type:        nodemaintenanceapi.PDBPolicyBypassAfter,
+				BypassAfter: &metav1.Duration{Duration: time.Hour},
+			}
+			startTime := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			nm.Status.StartTime = &startTime
+			podList, err := r.getPodsForDeletion(nm.Spec.NodeName)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.recordViolatedPDBs(nm, podList)).To(Succeed())
+			Expect(nm.Status.PDBBypassTime).NotTo(BeNil())
+			bypassTime := *nm.Status.PDBBypassTime
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(ContainSubstring(EventReasonPDBBypassed))
+			Expect(<-recorder.Events).To(ContainSubstring(EventReasonPDBViolated))
+
+			// requeued, independent of the last reconcile time, a PDB which is violated by a new pod is still announced
+			lastReconcileTime := metav1.NewTime(time.Now().Add(-90 * time.Minute))
+			nm.Status.LastReconcileTime = &lastReconcileTime
+			_, err = clientset.CoreV1().Pods("default").Create(context.TODO(), getPod("other", "other"), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = clientset.PolicyV1beta1().PodDisruptionBudgets("default").Create(context.TODO(), getPDB("pdb-other", "other", 0), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			podList, err = r.getPodsForDeletion(nm.Spec.NodeName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.recordViolatedPDBs(nm, podList)).To(Succeed())
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(ContainSubstring(EventReasonPDBViolated))
+			Expect(*nm.Status.PDBBypassTime).To(Equal(bypassTime))
+		})
+
+		It("should record policy/v1 PDBs, with empty selectors matching all pods", func() {
+			clientset := k8sfake.NewSimpleClientset(
+				getPod("blocked", "blocked"),
+				&policyv1.PodDisruptionBudget{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb-all"},
+					Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{}},
+				},
+				// policy/v1beta1 PDBs aren't read when policy/v1 is supported
+				getPDB("pdb-v1beta1", "blocked", 0),
+			)
+			r := &NodeMaintenanceReconciler{
+				drainer:             &drain.Helper{Client: clientset, Ctx: context.Background(), Force: true},
+				isPolicyV1Supported: true,
+				recorder:            record.NewFakeRecorder(10),
+				logger:              ctrl.Log.WithName("test"),
+			}
+			nm := getTestNM()
+			podList, err := r.getPodsForDeletion(nm.Spec.NodeName)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.recordViolatedPDBs(nm, podList)).To(Succeed())
+			Expect(nm.Status.ViolatedPDBs).To(Equal([]string{"default/pdb-all"}))
+		})
+	})
+})
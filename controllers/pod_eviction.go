@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/drain"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// disruptionBudgetCause is the cause type of evictions rejected because of a PodDisruptionBudget
+const disruptionBudgetCause = "DisruptionBudget"
+
+// PodEvictionProbeInterval is the interval of the dry run evictions of a pod, which wasn't evicted yet.
+// Drain attempts within the same interval keep the reason of the previous probe.
+const PodEvictionProbeInterval = time.Minute
+
+// updatePodEvictions updates the eviction status of the pods, which weren't evicted by the latest drain attempt.
+// The reason is checked with a dry run eviction, for the first MaxStatusPods pods only, and at most once per
+// PodEvictionProbeInterval for every pod, in order to limit the API calls of every drain attempt on nodes with many pods.
+// Without eviction, i.e. when PDBs are bypassed, pods are deleted and there is nothing to probe.
+func (r *NodeMaintenanceReconciler) updatePodEvictions(nm *nodemaintenancev1beta1.NodeMaintenance, podList *drain.PodDeleteList, evictionDisabled bool) {
+	previous := map[string]nodemaintenancev1beta1.PodEvictionStatus{}
+	for _, status := range nm.Status.PodEvictions {
+		previous[status.Namespace+"/"+status.Name] = status
+	}
+
+	now := metav1.Now()
+	var podEvictions []nodemaintenancev1beta1.PodEvictionStatus
+	for _, pod := range podList.Pods() {
+		if len(podEvictions) == MaxStatusPods {
+			break
+		}
+		status, found := previous[pod.Namespace+"/"+pod.Name]
+		if !found {
+			status = nodemaintenancev1beta1.PodEvictionStatus{
+				Namespace:        pod.Namespace,
+				Name:             pod.Name,
+				FirstAttemptTime: now,
+			}
+			if owner := metav1.GetControllerOf(&pod); owner != nil {
+				status.OwnerKind = owner.Kind
+				status.OwnerName = owner.Name
+			}
+		}
+		probe := !evictionDisabled && (!found || isEvictionProbeDue(status, now.Time))
+		status.Attempts++
+		status.LastAttemptTime = now
+
+		switch {
+		case probe:
+			err := r.probeEviction(pod)
+			if errors.IsNotFound(err) {
+				// gone in the meantime
+				continue
+			}
+			status.Reason = getPodEvictionReason(pod, err)
+			status.LastError = ""
+			if err != nil {
+				status.LastError = err.Error()
+			}
+		case evictionDisabled || pod.DeletionTimestamp != nil:
+			status.Reason = getPodEvictionReason(pod, nil)
+			status.LastError = ""
+		}
+		podEvictions = append(podEvictions, status)
+	}
+	nm.Status.FailedPodsCount = 0
+	for _, status := range podEvictions {
+		if status.Reason != nodemaintenancev1beta1.PodEvictionPending && status.Reason != nodemaintenancev1beta1.PodEvictionTerminating {
+			nm.Status.FailedPodsCount++
+		}
+	}
+	nm.Status.PodEvictions = podEvictions
+}
+
+// isEvictionProbeDue returns true if the eviction of the pod with the given status wasn't probed yet
+// in the current PodEvictionProbeInterval since its first attempt
+func isEvictionProbeDue(status nodemaintenancev1beta1.PodEvictionStatus, now time.Time) bool {
+	interval := func(t time.Time) time.Duration {
+		return t.Sub(status.FirstAttemptTime.Time) / PodEvictionProbeInterval
+	}
+	return interval(now) > interval(status.LastAttemptTime.Time)
+}
+
+// probeEviction evicts the given pod with dry run, in order to find out why it wasn't evicted yet
+func (r *NodeMaintenanceReconciler) probeEviction(pod corev1.Pod) error {
+	objectMeta := metav1.ObjectMeta{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+	}
+	deleteOptions := &metav1.DeleteOptions{
+		DryRun: []string{metav1.DryRunAll},
+	}
+	if r.isPolicyV1Supported {
+		eviction := &policyv1.Eviction{ObjectMeta: objectMeta, DeleteOptions: deleteOptions}
+		return r.drainer.Client.PolicyV1().Evictions(pod.Namespace).Evict(context.Background(), eviction)
+	}
+	eviction := &policyv1beta1.Eviction{ObjectMeta: objectMeta, DeleteOptions: deleteOptions}
+	return r.drainer.Client.PolicyV1beta1().Evictions(pod.Namespace).Evict(context.Background(), eviction)
+}
+
+// getPodEvictionReason returns the reason why the given pod wasn't evicted yet, based on the error of its dry run eviction
+func getPodEvictionReason(pod corev1.Pod, err error) nodemaintenancev1beta1.PodEvictionReason {
+	switch {
+	case err == nil && pod.DeletionTimestamp != nil:
+		return nodemaintenancev1beta1.PodEvictionTerminating
+	case err == nil:
+		return nodemaintenancev1beta1.PodEvictionPending
+	case errors.IsTooManyRequests(err) && isDisruptionBudgetError(err):
+		return nodemaintenancev1beta1.PodEvictionPDBBlocked
+	case errors.IsTooManyRequests(err):
+		return nodemaintenancev1beta1.PodEvictionTooManyRequests
+	case errors.IsInternalError(err):
+		return nodemaintenancev1beta1.PodEvictionServerError
+	default:
+		return nodemaintenancev1beta1.PodEvictionFailed
+	}
+}
+
+// isDisruptionBudgetError returns true if the eviction was rejected because of a PodDisruptionBudget
+func isDisruptionBudgetError(err error) bool {
+	if status, ok := err.(errors.APIStatus); ok && status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			if cause.Type == disruptionBudgetCause {
+				return true
+			}
+		}
+	}
+	// older API servers don't set the cause
+	return strings.Contains(err.Error(), "disruption budget")
+}
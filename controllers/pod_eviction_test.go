@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/kubectl/pkg/drain"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+var _ = Describe("Pod eviction status", func() {
+
+	var r *NodeMaintenanceReconciler
+	var nm *nodemaintenanceapi.NodeMaintenance
+	var clientset *k8sfake.Clientset
+	var evictionVersions []string
+
+	getPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "ReplicaSet",
+					Name:       name + "-rs",
+					Controller: &[]bool{true}[0],
+				}},
+			},
+			Spec: corev1.PodSpec{NodeName: "node01"},
+		}
+	}
+
+	BeforeEach(func() {
+		evictionVersions = nil
+		terminating := getPod("terminating")
+		terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		clientset = k8sfake.NewSimpleClientset(
+			getPod("pdb-blocked"), getPod("too-many-requests"), getPod("server-error"), getPod("pending"), terminating,
+		)
+		clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			var eviction policyv1.Eviction
+			switch obj := action.(k8stesting.CreateAction).GetObject().(type) {
+			case *policyv1.Eviction:
+				evictionVersions = append(evictionVersions, "v1")
+				eviction = *obj
+			case *policyv1beta1.Eviction:
+				evictionVersions = append(evictionVersions, "v1beta1")
+				eviction = policyv1.Eviction{ObjectMeta: obj.ObjectMeta, DeleteOptions: obj.DeleteOptions}
+			}
+			Expect(eviction.DeleteOptions.DryRun).To(Equal([]string{metav1.DryRunAll}))
+			switch eviction.Name {
+			case "pdb-blocked":
+				err := errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+				err.ErrStatus.Details.Causes = []metav1.StatusCause{{Type: disruptionBudgetCause}}
+				return true, nil, err
+			case "too-many-requests":
+				return true, nil, errors.NewTooManyRequests("slow down", 10)
+			case "server-error":
+				return true, nil, errors.NewInternalError(errors.NewBadRequest("pod has multiple PodDisruptionBudgets"))
+			case "gone":
+				return true, nil, errors.NewNotFound(schema.GroupResource{Resource: "pods"}, eviction.Name)
+			}
+			return true, nil, nil
+		})
+		r = &NodeMaintenanceReconciler{
+			drainer: &drain.Helper{Client: clientset, Ctx: context.Background()},
+			logger:  ctrl.Log.WithName("test"),
+		}
+		nm = getTestNM()
+	})
+
+	updatePodEvictionsWithEviction := func(evictionDisabled bool) {
+		podList, err := r.getPodsForDeletion(nm.Spec.NodeName)
+		Expect(err).NotTo(HaveOccurred())
+		r.updatePodEvictions(nm, podList, evictionDisabled)
+	}
+
+	updatePodEvictions := func() {
+		updatePodEvictionsWithEviction(false)
+	}
+
+	getPodEvictions := func() map[string]nodemaintenanceapi.PodEvictionStatus {
+		podEvictions := map[string]nodemaintenanceapi.PodEvictionStatus{}
+		for _, status := range nm.Status.PodEvictions {
+			podEvictions[status.Name] = status
+		}
+		return podEvictions
+	}
+
+	It("should report the reason of every pending pod", func() {
+		updatePodEvictions()
+
+		podEvictions := getPodEvictions()
+		Expect(podEvictions).To(HaveLen(5))
+		Expect(podEvictions["pdb-blocked"].Reason).To(Equal(nodemaintenanceapi.PodEvictionPDBBlocked))
+		Expect(podEvictions["pdb-blocked"].LastError).To(ContainSubstring("disruption budget"))
+		Expect(podEvictions["too-many-requests"].Reason).To(Equal(nodemaintenanceapi.PodEvictionTooManyRequests))
+		Expect(podEvictions["server-error"].Reason).To(Equal(nodemaintenanceapi.PodEvictionServerError))
+		Expect(podEvictions["pending"].Reason).To(Equal(nodemaintenanceapi.PodEvictionPending))
+		Expect(podEvictions["pending"].LastError).To(BeEmpty())
+		Expect(podEvictions["terminating"].Reason).To(Equal(nodemaintenanceapi.PodEvictionTerminating))
+
+		Expect(podEvictions["pending"].Namespace).To(Equal("default"))
+		Expect(podEvictions["pending"].OwnerKind).To(Equal("ReplicaSet"))
+		Expect(podEvictions["pending"].OwnerName).To(Equal("pending-rs"))
+		Expect(podEvictions["pending"].Attempts).To(Equal(1))
+		Expect(evictionVersions).To(ConsistOf("v1beta1", "v1beta1", "v1beta1", "v1beta1", "v1beta1"))
+	})
+
+	It("should probe with policy/v1 evictions when supported", func() {
+		r.isPolicyV1Supported = true
+		updatePodEvictions()
+
+		Expect(getPodEvictions()["pdb-blocked"].Reason).To(Equal(nodemaintenanceapi.PodEvictionPDBBlocked))
+		Expect(evictionVersions).NotTo(BeEmpty())
+		Expect(evictionVersions).NotTo(ContainElement("v1beta1"))
+	})
+
+	It("should count the attempts and drop evicted pods", func() {
+		updatePodEvictions()
+		firstAttemptTime := getPodEvictions()["pdb-blocked"].FirstAttemptTime
+
+		Expect(clientset.CoreV1().Pods("default").Delete(context.TODO(), "pending", metav1.DeleteOptions{})).To(Succeed())
+		updatePodEvictions()
+
+		podEvictions := getPodEvictions()
+		Expect(podEvictions).To(HaveLen(4))
+		Expect(podEvictions).NotTo(HaveKey("pending"))
+		Expect(podEvictions["pdb-blocked"].Attempts).To(Equal(2))
+		Expect(podEvictions["pdb-blocked"].FirstAttemptTime).To(Equal(firstAttemptTime))
+	})
+
+	It("should probe at most the pods listed in the status", func() {
+		for i := 0; i < MaxStatusPods; i++ {
+			_, err := clientset.CoreV1().Pods("default").Create(context.TODO(), getPod(fmt.Sprintf("pod-%d", i)), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		updatePodEvictions()
+		Expect(nm.Status.PodEvictions).To(HaveLen(MaxStatusPods))
+		Expect(evictionVersions).To(HaveLen(MaxStatusPods))
+	})
+
+	It("should drop pods which are gone during the eviction", func() {
+		_, err := clientset.CoreV1().Pods("default").Create(context.TODO(), getPod("gone"), metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		updatePodEvictions()
+		Expect(getPodEvictions()).NotTo(HaveKey("gone"))
+	})
+
+	It("should probe every pod at most once per probe interval", func() {
+		updatePodEvictions()
+		updatePodEvictions()
+		Expect(evictionVersions).To(HaveLen(5))
+		Expect(getPodEvictions()["pdb-blocked"].Attempts).To(Equal(2))
+		Expect(getPodEvictions()["pdb-blocked"].Reason).To(Equal(nodemaintenanceapi.PodEvictionPDBBlocked))
+
+		// move the previous attempts into the previous probe interval
+		for i := range nm.Status.PodEvictions {
+			status := &nm.Status.PodEvictions[i]
+			status.FirstAttemptTime = metav1.NewTime(status.FirstAttemptTime.Add(-PodEvictionProbeInterval))
+			status.LastAttemptTime = metav1.NewTime(status.LastAttemptTime.Add(-PodEvictionProbeInterval))
+		}
+		updatePodEvictions()
+		Expect(evictionVersions).To(HaveLen(10))
+	})
+
+	It("should not probe pods when eviction is disabled", func() {
+		updatePodEvictionsWithEviction(true)
+		Expect(evictionVersions).To(BeEmpty())
+
+		podEvictions := getPodEvictions()
+		Expect(podEvictions).To(HaveLen(5))
+		Expect(podEvictions["pdb-blocked"].Reason).To(Equal(nodemaintenanceapi.PodEvictionPending))
+		Expect(podEvictions["pdb-blocked"].LastError).To(BeEmpty())
+		Expect(podEvictions["terminating"].Reason).To(Equal(nodemaintenanceapi.PodEvictionTerminating))
+		Expect(nm.Status.FailedPodsCount).To(BeZero())
+	})
+})
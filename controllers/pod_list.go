@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// MaxStatusPods is the maximum number of pods in the pod lists of the status of a NodeMaintenance
+const MaxStatusPods = 50
+
+const (
+	podListConfigMapPendingKey = "pendingPods"
+	podListConfigMapEvictedKey = "evictedPods"
+
+	podListConfigMapPrefix = "nodemaintenance-"
+	podListConfigMapSuffix = "-pods"
+	// max length of the NodeMaintenance name in the name of the pod list ConfigMap
+	maxPodListConfigMapNameLength = 253 - len(podListConfigMapPrefix) - len(podListConfigMapSuffix)
+	// podListConfigMapHashLength is the length of the hash suffix of shortened NodeMaintenance names
+	podListConfigMapHashLength = 10
+)
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+
+// setPendingPods updates the pending and evicted pods of the status with the currently pending pods,
+// which are identified by namespace/name.
+// The lists of the status are capped at MaxStatusPods, while the counts cover all pods.
+// The complete lists are stored in a ConfigMap if SpillPodLists is enabled.
+func (r *NodeMaintenanceReconciler) setPendingPods(nm *nodemaintenancev1beta1.NodeMaintenance, pendingPods []string) error {
+	previousPending, evicted, err := r.getPodLists(nm)
+	if err != nil {
+		return err
+	}
+	evicted = AppendEvictedPods(evicted, previousPending, pendingPods)
+
+	// the pods which were pending before and aren't pending anymore were evicted
+	previousCount := nm.Status.PendingPodsCount
+	if previousCount < len(previousPending) {
+		// status written before the counts existed
+		previousCount = len(previousPending)
+	}
+	// pods beyond a capped previous list are unknown, they are assumed to be pending before
+	previousCapped := len(previousPending) < previousCount
+	stillPending := 0
+	for _, pod := range pendingPods {
+		if previousCapped || containsPod(previousPending, pod) {
+			stillPending++
+		}
+	}
+	if evictedCount := previousCount - stillPending; evictedCount > 0 {
+		nm.Status.EvictedPodsCount += evictedCount
+	}
+	if nm.Status.EvictedPodsCount < len(evicted) {
+		// status written before the counts existed
+		nm.Status.EvictedPodsCount = len(evicted)
+	}
+	nm.Status.PendingPodsCount = len(pendingPods)
+
+	if r.SpillPodLists {
+		if err := r.savePodListConfigMap(nm, pendingPods, evicted); err != nil {
+			return err
+		}
+	}
+	nm.Status.PendingPods = capPodList(pendingPods)
+	nm.Status.EvictedPods = capPodList(evicted)
+	return nil
+}
+
+// getPodLists returns the pending and evicted pods of the maintenance, from its pod list ConfigMap if it exists.
+// Otherwise the lists of the status are returned, which are incomplete if they were capped.
+func (r *NodeMaintenanceReconciler) getPodLists(nm *nodemaintenancev1beta1.NodeMaintenance) ([]string, []string, error) {
+	if ref := nm.Status.PodListConfigMap; ref != nil {
+		cm, err := r.drainer.Client.CoreV1().ConfigMaps(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err == nil {
+			return splitPodList(cm.Data[podListConfigMapPendingKey]), splitPodList(cm.Data[podListConfigMapEvictedKey]), nil
+		}
+		if !errors.IsNotFound(err) {
+			return nil, nil, err
+		}
+	}
+	return nm.Status.PendingPods, nm.Status.EvictedPods, nil
+}
+
+// savePodListConfigMap stores the complete pod lists in the pod list ConfigMap of the maintenance in the operator namespace.
+// The ConfigMap is owned by the NodeMaintenance, so that it is garbage collected with it.
+func (r *NodeMaintenanceReconciler) savePodListConfigMap(nm *nodemaintenancev1beta1.NodeMaintenance, pendingPods []string, evictedPods []string) error {
+	data := map[string]string{
+		podListConfigMapPendingKey: strings.Join(pendingPods, "\n"),
+		podListConfigMapEvictedKey: strings.Join(evictedPods, "\n"),
+	}
+	configMaps := r.drainer.Client.CoreV1().ConfigMaps(r.LeaseNamespace)
+	name := podListConfigMapName(nm)
+
+	cm, err := configMaps.Get(context.TODO(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: r.LeaseNamespace,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(nm, nodemaintenancev1beta1.GroupVersion.WithKind("NodeMaintenance")),
+				},
+			},
+			Data: data,
+		}
+		_, err = configMaps.Create(context.TODO(), cm, metav1.CreateOptions{})
+	} else if err == nil && !reflect.DeepEqual(cm.Data, data) {
+		// the lists are saved on every reconcile, but only change while pods are evicted
+		cm.Data = data
+		_, err = configMaps.Update(context.TODO(), cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	nm.Status.PodListConfigMap = &nodemaintenancev1beta1.ConfigMapReference{
+		Namespace: r.LeaseNamespace,
+		Name:      name,
+	}
+	return nil
+}
+
+// podListConfigMapName returns the name of the pod list ConfigMap of the given maintenance.
+// Long NodeMaintenance names are shortened to a prefix of the name and a hash of the full name,
+// so that the ConfigMap name doesn't exceed the maximum length of resource names.
+func podListConfigMapName(nm *nodemaintenancev1beta1.NodeMaintenance) string {
+	name := nm.Name
+	if len(name) > maxPodListConfigMapNameLength {
+		hash := sha256.Sum256([]byte(name))
+		name = name[:maxPodListConfigMapNameLength-podListConfigMapHashLength-1] + "-" + hex.EncodeToString(hash[:])[:podListConfigMapHashLength]
+	}
+	return podListConfigMapPrefix + name + podListConfigMapSuffix
+}
+
+// splitPodList returns the pods of a pod list of the pod list ConfigMap
+func splitPodList(podList string) []string {
+	if podList == "" {
+		return nil
+	}
+	return strings.Split(podList, "\n")
+}
+
+// capPodList returns the first MaxStatusPods pods of the given list
+func capPodList(pods []string) []string {
+	if len(pods) > MaxStatusPods {
+		return pods[:MaxStatusPods]
+	}
+	return pods
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubectl/pkg/drain"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+var _ = Describe("Pod lists", func() {
+
+	var r *NodeMaintenanceReconciler
+	var nm *nodemaintenanceapi.NodeMaintenance
+	var clientset *k8sfake.Clientset
+
+	getPods := func(from int, to int) (pods []string) {
+		for i := from; i < to; i++ {
+			pods = append(pods, fmt.Sprintf("pod-%d", i))
+		}
+		return pods
+	}
+
+	BeforeEach(func() {
+		clientset = k8sfake.NewSimpleClientset()
+		r = &NodeMaintenanceReconciler{
+			LeaseNamespace: LeaseNamespaceDefault,
+			drainer:        &drain.Helper{Client: clientset, Ctx: context.Background()},
+			logger:         ctrl.Log.WithName("test"),
+		}
+		nm = getTestNM()
+	})
+
+	It("should cap the lists and count all pods", func() {
+		Expect(r.setPendingPods(nm, getPods(0, 200))).To(Succeed())
+		Expect(nm.Status.PendingPods).To(HaveLen(MaxStatusPods))
+		Expect(nm.Status.PendingPodsCount).To(Equal(200))
+		Expect(nm.Status.PodListConfigMap).To(BeNil())
+
+		Expect(r.setPendingPods(nm, getPods(20, 200))).To(Succeed())
+		Expect(nm.Status.PendingPods).To(Equal(getPods(20, 20+MaxStatusPods)))
+		Expect(nm.Status.EvictedPods).To(Equal(getPods(0, 20)))
+		Expect(nm.Status.PendingPodsCount).To(Equal(180))
+		Expect(nm.Status.EvictedPodsCount).To(Equal(20))
+
+		// only the evicted pods which were listed are known by name
+		Expect(r.setPendingPods(nm, getPods(150, 200))).To(Succeed())
+		Expect(nm.Status.EvictedPods).To(HaveLen(MaxStatusPods))
+		Expect(nm.Status.PendingPodsCount).To(Equal(50))
+		Expect(nm.Status.EvictedPodsCount).To(Equal(150))
+
+		Expect(r.setPendingPods(nm, nil)).To(Succeed())
+		Expect(nm.Status.PendingPods).To(BeEmpty())
+		Expect(nm.Status.PendingPodsCount).To(BeZero())
+		Expect(nm.Status.EvictedPodsCount).To(Equal(200))
+	})
+
+	It("should count the evicted pods of statuses without counts", func() {
+		nm.Status.PendingPods = []string{"pod-a", "pod-b"}
+		nm.Status.EvictedPods = []string{"pod-c"}
+		Expect(r.setPendingPods(nm, []string{"pod-b"})).To(Succeed())
+		Expect(nm.Status.EvictedPods).To(Equal([]string{"pod-c", "pod-a"}))
+		Expect(nm.Status.EvictedPodsCount).To(Equal(2))
+		Expect(nm.Status.PendingPodsCount).To(Equal(1))
+	})
+
+	It("should spill the complete lists to a ConfigMap", func() {
+		r.SpillPodLists = true
+		Expect(r.setPendingPods(nm, getPods(0, 200))).To(Succeed())
+		Expect(r.setPendingPods(nm, getPods(100, 200))).To(Succeed())
+		Expect(nm.Status.PendingPods).To(HaveLen(MaxStatusPods))
+		Expect(nm.Status.EvictedPods).To(HaveLen(MaxStatusPods))
+		Expect(nm.Status.EvictedPodsCount).To(Equal(100))
+
+		Expect(nm.Status.PodListConfigMap).NotTo(BeNil())
+		Expect(nm.Status.PodListConfigMap.Namespace).To(Equal(LeaseNamespaceDefault))
+		cm, err := clientset.CoreV1().ConfigMaps(LeaseNamespaceDefault).Get(context.TODO(), nm.Status.PodListConfigMap.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.OwnerReferences).To(HaveLen(1))
+		Expect(cm.OwnerReferences[0].Kind).To(Equal("NodeMaintenance"))
+
+		pending, evicted, err := r.getPodLists(nm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending).To(Equal(getPods(100, 200)))
+		Expect(evicted).To(Equal(getPods(0, 100)))
+
+		Expect(r.setPendingPods(nm, nil)).To(Succeed())
+		_, evicted, err = r.getPodLists(nm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(evicted).To(Equal(getPods(0, 200)))
+		Expect(nm.Status.EvictedPodsCount).To(Equal(200))
+	})
+
+	It("should not update the ConfigMap when the lists didn't change", func() {
+		r.SpillPodLists = true
+		Expect(r.setPendingPods(nm, getPods(0, 100))).To(Succeed())
+		Expect(r.setPendingPods(nm, getPods(0, 100))).To(Succeed())
+		Expect(r.setPendingPods(nm, getPods(50, 100))).To(Succeed())
+
+		var updates int
+		for _, action := range clientset.Actions() {
+			if action.Matches("update", "configmaps") {
+				updates++
+			}
+		}
+		Expect(updates).To(Equal(1))
+	})
+
+	It("should count pods with the same name in different namespaces", func() {
+		Expect(r.setPendingPods(nm, []string{"ns1/pod", "ns2/pod"})).To(Succeed())
+		Expect(r.setPendingPods(nm, []string{"ns2/pod"})).To(Succeed())
+		Expect(nm.Status.PendingPods).To(Equal([]string{"ns2/pod"}))
+		Expect(nm.Status.EvictedPods).To(Equal([]string{"ns1/pod"}))
+		Expect(nm.Status.EvictedPodsCount).To(Equal(1))
+	})
+
+	It("should shorten the ConfigMap name of long NodeMaintenance names", func() {
+		nm.Name = strings.Repeat("a", 253)
+		name := podListConfigMapName(nm)
+		Expect(len(name)).To(BeNumerically("<=", 253))
+
+		other := nm.DeepCopy()
+		other.Name = strings.Repeat("a", 252) + "b"
+		Expect(podListConfigMapName(other)).NotTo(Equal(name))
+
+		r.SpillPodLists = true
+		Expect(r.setPendingPods(nm, getPods(0, 10))).To(Succeed())
+		Expect(nm.Status.PodListConfigMap.Name).To(Equal(name))
+
+		nm.Name = "short"
+		Expect(podListConfigMapName(nm)).To(Equal("nodemaintenance-short-pods"))
+	})
+})
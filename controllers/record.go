@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+const (
+	// max length of a resource name, minus the length of an UID and a dash
+	maxRecordNamePrefixLength = 253 - 37
+
+	// recordRetryTimeout is the time since the deletion of a NodeMaintenance after which failing to record it
+	// doesn't block the removal of its finalizer anymore
+	recordRetryTimeout = 10 * time.Minute
+
+	// EventReasonRecordFailed is the reason of the event emitted when the history of a maintenance couldn't be recorded
+	EventReasonRecordFailed = "RecordFailed"
+)
+
+// getRecordName returns the name of the NodeMaintenanceRecord of the given NodeMaintenance
+func getRecordName(nm *nodemaintenancev1beta1.NodeMaintenance) string {
+	prefix := nm.Name
+	if len(prefix) > maxRecordNamePrefixLength {
+		prefix = prefix[:maxRecordNamePrefixLength]
+	}
+	return fmt.Sprintf("%s-%s", prefix, nm.UID)
+}
+
+// newMaintenanceRecord returns a NodeMaintenanceRecord with the history of the given NodeMaintenance
+func newMaintenanceRecord(nm *nodemaintenancev1beta1.NodeMaintenance) *nodemaintenancev1beta1.NodeMaintenanceRecord {
+	startTime := nm.CreationTimestamp
+	if nm.Status.StartTime != nil {
+		startTime = *nm.Status.StartTime
+	}
+	endTime := metav1.Now()
+	if nm.Status.EndTime != nil {
+		endTime = *nm.Status.EndTime
+	} else if nm.DeletionTimestamp != nil {
+		endTime = *nm.DeletionTimestamp
+	}
+
+	return &nodemaintenancev1beta1.NodeMaintenanceRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: getRecordName(nm),
+			Labels: map[string]string{
+				nodemaintenancev1beta1.RecordNodeNameLabel:        nodemaintenancev1beta1.RecordLabelValue(nm.Spec.NodeName),
+				nodemaintenancev1beta1.RecordNodeMaintenanceLabel: nodemaintenancev1beta1.RecordLabelValue(nm.Name),
+			},
+		},
+		Spec: nm.Spec,
+		Status: nodemaintenancev1beta1.NodeMaintenanceRecordStatus{
+			NodeMaintenanceName: nm.Name,
+			NodeMaintenanceUID:  nm.UID,
+			Requester:           nm.Annotations[nodemaintenancev1beta1.RequesterAnnotation],
+			RequesterGroups:     getRequesterGroups(nm),
+			StartTime:           &startTime,
+			DrainCompletedTime:  nm.Status.DrainCompletedTime,
+			EndTime:             &endTime,
+			PhaseTransitions:    nm.Status.PhaseTransitions,
+			Phase:               nm.Status.Phase,
+			LastError:           nm.Status.LastError,
+			TotalPods:           nm.Status.TotalPods,
+			EvictionPods:        nm.Status.EvictionPods,
+			EvictedPods:         nm.Status.EvictedPods,
+			PendingPods:         nm.Status.PendingPods,
+		},
+	}
+}
+
+// getRequesterGroups returns the groups of the requester of the given NodeMaintenance
+func getRequesterGroups(nm *nodemaintenancev1beta1.NodeMaintenance) []string {
+	groups, ok := nm.Annotations[nodemaintenancev1beta1.RequesterGroupsAnnotation]
+	if !ok || groups == "" {
+		return nil
+	}
+	return strings.Split(groups, ",")
+}
+
+// recordMaintenance records the history of the given deleted NodeMaintenance. It returns an error if recording it failed
+// and should be retried before the finalizer is removed. Invalid records, and records still failing after the
+// recordRetryTimeout, are given up with a warning event, so that they don't block the deletion forever.
+func (r *NodeMaintenanceReconciler) recordMaintenance(nm *nodemaintenancev1beta1.NodeMaintenance) error {
+	err := createMaintenanceRecord(r.Client, nm)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsInvalid(err) && time.Since(nm.DeletionTimestamp.Time) < recordRetryTimeout {
+		return err
+	}
+	r.logger.Error(err, "giving up recording node maintenance")
+	r.recorder.Eventf(nm, corev1.EventTypeWarning, EventReasonRecordFailed,
+		"The history of the maintenance of node %s is lost: %v", nm.Spec.NodeName, err)
+	return nil
+}
+
+// createMaintenanceRecord creates the NodeMaintenanceRecord for the given NodeMaintenance, if it doesn't exist yet
+func createMaintenanceRecord(c client.Client, nm *nodemaintenancev1beta1.NodeMaintenance) error {
+	record := newMaintenanceRecord(nm)
+	if err := c.Create(context.TODO(), record); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to create NodeMaintenanceRecord %s: %w", record.Name, err)
+	}
+	return nil
+}
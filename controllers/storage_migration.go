@@ -0,0 +1,113 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	nodemaintenancev1 "kubevirt.io/node-maintenance-operator/api/v1"
+)
+
+const (
+	// NodeMaintenanceCRDName is the name of the NodeMaintenance CustomResourceDefinition
+	NodeMaintenanceCRDName = "nodemaintenances.nodemaintenance.kubevirt.io"
+	// storageMigrationRetryInterval is the interval of retrying a failed storage migration
+	storageMigrationRetryInterval = 30 * time.Second
+)
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=update;patch
+
+// StorageMigrator rewrites all NodeMaintenances which might still be stored in an older API version, so that they are
+// stored in the current storage version, and then removes the older versions from the stored versions of the CRD.
+// The older versions can only be removed from the CRD after that.
+type StorageMigrator struct {
+	client.Client
+}
+
+var _ manager.Runnable = &StorageMigrator{}
+var _ manager.LeaderElectionRunnable = &StorageMigrator{}
+
+// SetupWithManager adds the storage migrator to the Manager
+func (m *StorageMigrator) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(m)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only the leader migrates
+func (m *StorageMigrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable, it retries the migration until it succeeded or the context is done
+func (m *StorageMigrator) Start(ctx context.Context) error {
+	logger := ctrl.Log.WithName("storage-migration")
+	// the conversion webhook of the operator might not be ready yet, so errors are retried
+	_ = wait.PollImmediateUntil(storageMigrationRetryInterval, func() (bool, error) {
+		if err := m.migrate(ctx); err != nil {
+			logger.Error(err, "failed to migrate NodeMaintenances to the storage version, retrying")
+			return false, nil
+		}
+		return true, nil
+	}, ctx.Done())
+	return nil
+}
+
+// migrate rewrites all NodeMaintenances, unless the storage version is the only stored version of the CRD already
+func (m *StorageMigrator) migrate(ctx context.Context) error {
+	logger := ctrl.Log.WithName("storage-migration")
+	storageVersion := nodemaintenancev1.GroupVersion.Version
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := m.Get(ctx, client.ObjectKey{Name: NodeMaintenanceCRDName}, crd); err != nil {
+		return fmt.Errorf("could not get CRD %s: %v", NodeMaintenanceCRDName, err)
+	}
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storageVersion {
+		return nil
+	}
+
+	nmList := &nodemaintenancev1.NodeMaintenanceList{}
+	if err := m.List(ctx, nmList); err != nil {
+		return fmt.Errorf("could not list NodeMaintenances: %v", err)
+	}
+	for i := range nmList.Items {
+		nm := &nmList.Items[i]
+		// an unchanged update stores the NodeMaintenance in the storage version
+		if err := m.Update(ctx, nm); err != nil {
+			// deleted or updated in the meantime, which stored it in the storage version as well
+			if errors.IsNotFound(err) || errors.IsConflict(err) {
+				continue
+			}
+			return fmt.Errorf("could not migrate NodeMaintenance %s: %v", nm.Name, err)
+		}
+	}
+
+	crd.Status.StoredVersions = []string{storageVersion}
+	if err := m.Status().Update(ctx, crd); err != nil {
+		return fmt.Errorf("could not update stored versions of CRD %s: %v", NodeMaintenanceCRDName, err)
+	}
+	logger.Info("migrated NodeMaintenances to the storage version", "version", storageVersion, "count", len(nmList.Items))
+	return nil
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nodemaintenancev1 "kubevirt.io/node-maintenance-operator/api/v1"
+)
+
+var _ = Describe("Storage migration", func() {
+
+	var cl client.Client
+	var migrator *StorageMigrator
+
+	getCRD := func(storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: NodeMaintenanceCRDName},
+			Status:     apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+		}
+	}
+
+	getNM := func(name string) *nodemaintenancev1.NodeMaintenance {
+		return &nodemaintenancev1.NodeMaintenance{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       nodemaintenancev1.NodeMaintenanceSpec{NodeName: name},
+		}
+	}
+
+	build := func(objs ...client.Object) {
+		testScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+		Expect(apiextensionsv1.AddToScheme(testScheme)).To(Succeed())
+		Expect(nodemaintenancev1.AddToScheme(testScheme)).To(Succeed())
+		cl = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(objs...).Build()
+		migrator = &StorageMigrator{Client: cl}
+	}
+
+	getResourceVersion := func(name string) string {
+		nm := &nodemaintenancev1.NodeMaintenance{}
+		Expect(cl.Get(context.TODO(), client.ObjectKey{Name: name}, nm)).To(Succeed())
+		return nm.ResourceVersion
+	}
+
+	It("should rewrite all NodeMaintenances and remove the old stored versions", func() {
+		build(getCRD("v1beta1", "v1"), getNM("node01"), getNM("node02"))
+		resourceVersion := getResourceVersion("node01")
+
+		Expect(migrator.migrate(context.TODO())).To(Succeed())
+		Expect(getResourceVersion("node01")).NotTo(Equal(resourceVersion))
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(cl.Get(context.TODO(), client.ObjectKey{Name: NodeMaintenanceCRDName}, crd)).To(Succeed())
+		Expect(crd.Status.StoredVersions).To(Equal([]string{"v1"}))
+	})
+
+	It("should not rewrite NodeMaintenances when they are stored as v1 only", func() {
+		build(getCRD("v1"), getNM("node01"))
+		resourceVersion := getResourceVersion("node01")
+
+		Expect(migrator.migrate(context.TODO())).To(Succeed())
+		Expect(getResourceVersion("node01")).To(Equal(resourceVersion))
+	})
+
+	It("should fail without CRD", func() {
+		build(getNM("node01"))
+		Expect(migrator.migrate(context.TODO())).NotTo(Succeed())
+	})
+})
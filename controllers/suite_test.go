@@ -18,12 +18,18 @@ package controllers
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
 	"path/filepath"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -32,7 +38,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest/printer"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
+	nodemaintenancev1 "kubevirt.io/node-maintenance-operator/api/v1"
 	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
 	//+kubebuilder:scaffold:imports
 )
@@ -62,30 +70,42 @@ var _ = BeforeSuite(func() {
 
 func startTestEnv() {
 	By("bootstrapping test environment")
+	// NodeMaintenances are stored as v1, envtest configures the CRD to use the conversion webhook of the test manager,
+	// because both versions are registered as convertible in the scheme
+	err := nodemaintenancev1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = nodemaintenancev1beta1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = apiextensionsv1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	//+kubebuilder:scaffold:scheme
+
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths:     []string{filepath.Join("..", "config", "crd", "bases")},
 		ErrorIfCRDPathMissing: true,
 	}
 
-	var err error
 	cfg, err = testEnv.Start()
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cfg).NotTo(BeNil())
 
-	err = nodemaintenancev1beta1.AddToScheme(scheme.Scheme)
-	Expect(err).NotTo(HaveOccurred())
-
-	//+kubebuilder:scaffold:scheme
-
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
+	webhookInstallOptions := &testEnv.WebhookInstallOptions
 	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:             scheme.Scheme,
+		Host:               webhookInstallOptions.LocalServingHost,
+		Port:               webhookInstallOptions.LocalServingPort,
+		CertDir:            webhookInstallOptions.LocalServingCertDir,
 		MetricsBindAddress: "0",
 	})
 	Expect(err).ToNot(HaveOccurred())
+	k8sManager.GetWebhookServer().Register("/convert", &conversion.Webhook{})
 
 	// comment in when moving to "normal" testEnv test
 	// this isn't needed atm, because the controller tests call relevant funcs of the controller themself
@@ -102,12 +122,52 @@ func startTestEnv() {
 		err = k8sManager.Start(ctxFromSignalHandler)
 		Expect(err).ToNot(HaveOccurred())
 	}()
+
+	// wait for the conversion webhook to get ready
+	dialer := &net.Dialer{Timeout: time.Second}
+	addrPort := fmt.Sprintf("%s:%d", webhookInstallOptions.LocalServingHost, webhookInstallOptions.LocalServingPort)
+	Eventually(func() error {
+		conn, err := tls.DialWithDialer(dialer, "tcp", addrPort, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return err
+		}
+		conn.Close()
+		return nil
+	}).Should(Succeed())
 }
 
 var _ = AfterSuite(func() {
 	// call stop or refactor when moving to "normal" testEnv test
 })
 
+// nodeNameIndexClient emulates the spec.nodeName field index of the cache for NodeMaintenances,
+// since the API server doesn't support field selectors on custom resources
+type nodeNameIndexClient struct {
+	client.Client
+}
+
+func (c nodeNameIndexClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	nmList, ok := list.(*nodemaintenancev1beta1.NodeMaintenanceList)
+	if !ok {
+		return c.Client.List(ctx, list, opts...)
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	selector := listOpts.FieldSelector
+	listOpts.FieldSelector = nil
+	if err := c.Client.List(ctx, nmList, listOpts); err != nil || selector == nil {
+		return err
+	}
+	var items []nodemaintenancev1beta1.NodeMaintenance
+	for _, nm := range nmList.Items {
+		if selector.Matches(fields.Set{nodemaintenancev1beta1.NodeNameField: nm.Spec.NodeName}) {
+			items = append(items, nm)
+		}
+	}
+	nmList.Items = items
+	return nil
+}
+
 func stopTestEnv() {
 	By("tearing down the test environment")
 	err := testEnv.Stop()
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/kubectl/pkg/drain"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// UnreachableNodeSkipWaitForDeleteTimeoutSeconds is the time pods of unreachable nodes are waited for after their deletion.
+// Their deletion can't complete, since the kubelet doesn't confirm it.
+const UnreachableNodeSkipWaitForDeleteTimeoutSeconds = 60
+
+// isNodeUnreachable returns true if the Ready condition of the node is Unknown, i.e. the node controller didn't hear from its kubelet
+func isNodeUnreachable(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionUnknown
+		}
+	}
+	return false
+}
+
+// getDrainer returns a copy of the drainer, configured for draining the given node.
+// Pods of unreachable nodes which are terminating for a while are skipped, instead of failing the drain forever.
+func (r *NodeMaintenanceReconciler) getDrainer(node *corev1.Node) *drain.Helper {
+	drainer := *r.drainer
+	if isNodeUnreachable(node) {
+		drainer.SkipWaitForDeleteTimeoutSeconds = UnreachableNodeSkipWaitForDeleteTimeoutSeconds
+	}
+	return &drainer
+}
+
+// forceDeleteTerminatingPods force deletes the pods of the node which are terminating for longer than the given duration,
+// and adds them to the force deleted pods of the status
+func (r *NodeMaintenanceReconciler) forceDeleteTerminatingPods(nm *nodemaintenancev1beta1.NodeMaintenance, after time.Duration) error {
+	podList := &corev1.PodList{}
+	if err := r.Client.List(context.TODO(), podList, client.MatchingFields{nodemaintenancev1beta1.NodeNameField: nm.Spec.NodeName}); err != nil {
+		return err
+	}
+
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp == nil || time.Since(pod.DeletionTimestamp.Time) < after {
+			continue
+		}
+		r.logger.Info("Force deleting terminating pod of unreachable node", "pod", pod.Name, "namespace", pod.Namespace, "nodeName", nm.Spec.NodeName)
+		err := r.Client.Delete(context.TODO(), &pod, client.GracePeriodSeconds(0))
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to force delete pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		podName := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+		if !ContainsString(nm.Status.ForceDeletedPods, podName) {
+			nm.Status.ForceDeletedPods = append(nm.Status.ForceDeletedPods, podName)
+		}
+	}
+	return nil
+}
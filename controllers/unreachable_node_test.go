@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/drain"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Unreachable node", func() {
+
+	getNode := func(ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node01"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+
+	getPod := func(name string, deletedSince *time.Duration) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.PodSpec{NodeName: "node01"},
+		}
+		if deletedSince != nil {
+			deletionTimestamp := metav1.NewTime(time.Now().Add(-*deletedSince))
+			pod.DeletionTimestamp = &deletionTimestamp
+		}
+		return pod
+	}
+
+	It("should detect unreachable nodes", func() {
+		Expect(isNodeUnreachable(getNode(corev1.ConditionUnknown))).To(BeTrue())
+		Expect(isNodeUnreachable(getNode(corev1.ConditionFalse))).To(BeFalse())
+		Expect(isNodeUnreachable(getNode(corev1.ConditionTrue))).To(BeFalse())
+		Expect(isNodeUnreachable(&corev1.Node{})).To(BeFalse())
+	})
+
+	It("should skip waiting for deleted pods of unreachable nodes only", func() {
+		r := &NodeMaintenanceReconciler{drainer: &drain.Helper{Timeout: DrainerTimeout}}
+
+		drainer := r.getDrainer(getNode(corev1.ConditionUnknown))
+		Expect(drainer.SkipWaitForDeleteTimeoutSeconds).To(Equal(UnreachableNodeSkipWaitForDeleteTimeoutSeconds))
+		Expect(drainer.Timeout).To(Equal(DrainerTimeout))
+		Expect(r.drainer.SkipWaitForDeleteTimeoutSeconds).To(BeZero())
+
+		drainer = r.getDrainer(getNode(corev1.ConditionTrue))
+		Expect(drainer.SkipWaitForDeleteTimeoutSeconds).To(BeZero())
+	})
+
+	It("should force delete pods which are terminating for too long", func() {
+		tenMinutes := 10 * time.Minute
+		oneMinute := time.Minute
+		cl := fake.NewClientBuilder().WithObjects(getPod("stuck", &tenMinutes), getPod("terminating", &oneMinute), getPod("running", nil)).Build()
+		r := &NodeMaintenanceReconciler{
+			Client: cl,
+			logger: ctrl.Log.WithName("test"),
+		}
+		nm := getTestNM()
+		nm.Status.ForceDeletedPods = []string{"default/stuck"}
+
+		Expect(r.forceDeleteTerminatingPods(nm, 5*time.Minute)).To(Succeed())
+		Expect(nm.Status.ForceDeletedPods).To(Equal([]string{"default/stuck"}))
+
+		err := cl.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "stuck"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		for _, name := range []string{"terminating", "running"} {
+			Expect(cl.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: name}, &corev1.Pod{})).To(Succeed())
+		}
+	})
+})
@@ -1,6 +1,13 @@
 package controllers
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
 
 // ContainsString checks if the string array contains the given string.
 func ContainsString(slice []string, s string) bool {
@@ -23,10 +30,58 @@ func RemoveString(slice []string, s string) (result []string) {
 	return result
 }
 
-// GetPodNameList returns a list of pod names from a pod list
+// GetPodNameList returns a list of pods, identified by namespace/name, from a pod list
 func GetPodNameList(pods []corev1.Pod) (result []string) {
 	for _, pod := range pods {
-		result = append(result, pod.ObjectMeta.Name)
+		result = append(result, pod.ObjectMeta.Namespace+"/"+pod.ObjectMeta.Name)
 	}
 	return result
 }
+
+// AppendEvictedPods appends the pods of the previous pending list, which aren't pending anymore, to the evicted list
+func AppendEvictedPods(evicted []string, previousPending []string, pending []string) []string {
+	for _, pod := range previousPending {
+		if !containsPod(pending, pod) && !containsPod(evicted, pod) {
+			evicted = append(evicted, pod)
+		}
+	}
+	return evicted
+}
+
+// containsPod returns true if the given pods, identified by namespace/name, contain the given pod.
+// Pod lists of former operator versions have pod names without namespace, these match the pods with that name in any namespace.
+func containsPod(pods []string, pod string) bool {
+	for _, p := range pods {
+		if isSamePod(p, pod) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSamePod returns true if the given pods, identified by namespace/name or by name only, are the same
+func isSamePod(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if strings.Contains(a, "/") && strings.Contains(b, "/") {
+		return false
+	}
+	return a[strings.Index(a, "/")+1:] == b[strings.Index(b, "/")+1:]
+}
+
+// AppendPhaseTransition appends a transition to the given phase if it differs from the phase of the latest transition,
+// and drops the oldest transitions when there are more than max transitions
+func AppendPhaseTransition(transitions []nodemaintenancev1beta1.PhaseTransition, phase nodemaintenancev1beta1.MaintenancePhase, time metav1.Time, max int) []nodemaintenancev1beta1.PhaseTransition {
+	if phase == "" || (len(transitions) > 0 && transitions[len(transitions)-1].Phase == phase) {
+		return transitions
+	}
+	transitions = append(transitions, nodemaintenancev1beta1.PhaseTransition{
+		Phase: phase,
+		Time:  time,
+	})
+	if len(transitions) > max {
+		transitions = transitions[len(transitions)-max:]
+	}
+	return transitions
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nodemaintenanceapi "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+var _ = Describe("Utils", func() {
+
+	Context("evicted pods", func() {
+
+		It("should append pods which aren't pending anymore", func() {
+			evicted := AppendEvictedPods([]string{"a"}, []string{"a", "b", "c", "d"}, []string{"c"})
+			Expect(evicted).To(Equal([]string{"a", "b", "d"}))
+		})
+
+		It("should append all previously pending pods when drain is done", func() {
+			evicted := AppendEvictedPods(nil, []string{"a", "b"}, nil)
+			Expect(evicted).To(Equal([]string{"a", "b"}))
+		})
+
+		It("should tell apart pods with the same name in different namespaces", func() {
+			evicted := AppendEvictedPods(nil, []string{"ns1/a", "ns2/a"}, []string{"ns2/a"})
+			Expect(evicted).To(Equal([]string{"ns1/a"}))
+		})
+
+		It("should match pod names without namespace of former operator versions", func() {
+			evicted := AppendEvictedPods([]string{"a"}, []string{"a", "b", "c"}, []string{"ns/b"})
+			Expect(evicted).To(Equal([]string{"a", "c"}))
+		})
+	})
+
+	Context("phase transitions", func() {
+
+		now := metav1.Now()
+		later := metav1.NewTime(now.Add(time.Minute))
+
+		It("should append a transition for a new phase", func() {
+			transitions := AppendPhaseTransition(nil, nodemaintenanceapi.MaintenanceRunning, now, 3)
+			transitions = AppendPhaseTransition(transitions, nodemaintenanceapi.MaintenanceSucceeded, later, 3)
+			Expect(transitions).To(Equal([]nodemaintenanceapi.PhaseTransition{
+				{Phase: nodemaintenanceapi.MaintenanceRunning, Time: now},
+				{Phase: nodemaintenanceapi.MaintenanceSucceeded, Time: later},
+			}))
+		})
+
+		It("should not append a transition for an unchanged or empty phase", func() {
+			transitions := AppendPhaseTransition(nil, nodemaintenanceapi.MaintenanceRunning, now, 3)
+			transitions = AppendPhaseTransition(transitions, nodemaintenanceapi.MaintenanceRunning, later, 3)
+			transitions = AppendPhaseTransition(transitions, "", later, 3)
+			Expect(transitions).To(Equal([]nodemaintenanceapi.PhaseTransition{
+				{Phase: nodemaintenanceapi.MaintenanceRunning, Time: now},
+			}))
+		})
+
+		It("should drop the oldest transitions", func() {
+			var transitions []nodemaintenanceapi.PhaseTransition
+			for _, phase := range []nodemaintenanceapi.MaintenancePhase{
+				nodemaintenanceapi.MaintenanceRunning,
+				nodemaintenanceapi.MaintenanceSucceeded,
+				nodemaintenanceapi.MaintenanceRunning,
+				nodemaintenanceapi.MaintenanceFailed,
+			} {
+				transitions = AppendPhaseTransition(transitions, phase, now, 3)
+			}
+			Expect(transitions).To(HaveLen(3))
+			Expect(transitions[0].Phase).To(Equal(nodemaintenanceapi.MaintenanceSucceeded))
+			Expect(transitions[2].Phase).To(Equal(nodemaintenanceapi.MaintenanceFailed))
+		})
+	})
+})
@@ -0,0 +1,59 @@
+#!/bin/bash
+set -ex
+
+# Generates the typed clientset, listers and informers of the NodeMaintenance API into pkg/client.
+# Expects client-gen, lister-gen and informer-gen in BIN_DIR, see the generate-client target of the Makefile.
+
+BIN_DIR=${BIN_DIR:-$(pwd)/bin}
+MODULE=kubevirt.io/node-maintenance-operator
+VERSIONS="v1 v1beta1"
+OUTPUT_PKG=${MODULE}/pkg/client
+HEADER_FILE=hack/boilerplate.go.txt
+
+# the generators write into a GOPATH like directory structure
+OUTPUT_BASE=$(mktemp -d)
+
+# the generators expect the API in a <group>/<version> directory, and treat "api/<version>" as the core group,
+# so they get a temporary link to the API, which is replaced by the real API package in the generated code
+GROUP_DIR=api/nodemaintenance
+INPUTS=""
+GEN_API_PKGS=""
+
+trap 'rm -rf "${OUTPUT_BASE}" "${GROUP_DIR}"' EXIT
+mkdir -p "${GROUP_DIR}"
+for VERSION in ${VERSIONS}; do
+  ln -s "../${VERSION}" "${GROUP_DIR}/${VERSION}"
+  INPUTS="${INPUTS:+${INPUTS},}nodemaintenance/${VERSION}"
+  GEN_API_PKGS="${GEN_API_PKGS:+${GEN_API_PKGS},}${MODULE}/${GROUP_DIR}/${VERSION}"
+done
+
+"${BIN_DIR}"/client-gen \
+  --clientset-name versioned \
+  --input-base "${MODULE}/api" \
+  --input "${INPUTS}" \
+  --output-package "${OUTPUT_PKG}/clientset" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER_FILE}"
+
+"${BIN_DIR}"/lister-gen \
+  --input-dirs "${GEN_API_PKGS}" \
+  --output-package "${OUTPUT_PKG}/listers" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER_FILE}"
+
+"${BIN_DIR}"/informer-gen \
+  --input-dirs "${GEN_API_PKGS}" \
+  --versioned-clientset-package "${OUTPUT_PKG}/clientset/versioned" \
+  --listers-package "${OUTPUT_PKG}/listers" \
+  --output-package "${OUTPUT_PKG}/informers" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER_FILE}"
+
+for VERSION in ${VERSIONS}; do
+  find "${OUTPUT_BASE}" -name '*.go' -exec sed -i "s|${MODULE}/${GROUP_DIR}/${VERSION}\"|${MODULE}/api/${VERSION}\"|g" {} +
+done
+
+# only the generated packages are replaced, which removes stale generated files but keeps the tests of the client
+rm -rf pkg/client/clientset pkg/client/listers pkg/client/informers
+mkdir -p pkg/client
+cp -r "${OUTPUT_BASE}/${OUTPUT_PKG}"/. pkg/client/
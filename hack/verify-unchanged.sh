@@ -3,5 +3,8 @@
 if [[ -n "$(git status --porcelain .)" ]]; then
     echo "Uncommitted generated files. Run 'make check' and commit results."
     echo "$(git status --porcelain .)"
+    if [[ -n "$(git status --porcelain pkg/client)" ]]; then
+        echo "The generated client in pkg/client is stale, run 'make generate-client' after changes of the API types."
+    fi
     exit 1
 fi
@@ -17,22 +17,30 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	nodemaintenancev1 "kubevirt.io/node-maintenance-operator/api/v1"
 	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
 	"kubevirt.io/node-maintenance-operator/controllers"
 	"kubevirt.io/node-maintenance-operator/version"
@@ -46,20 +54,81 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 
+	utilruntime.Must(nodemaintenancev1.AddToScheme(scheme))
 	utilruntime.Must(nodemaintenancev1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "cleanup" {
+		os.Exit(runCleanup(os.Args[2:]))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var recordRetention time.Duration
+	var defaultReason string
+	var defaultDrainTimeout time.Duration
+	var defaultFailurePolicy string
+	var defaultPDBBypassAfter time.Duration
+	var allowMultipleMaintenances bool
+	var leaseGCInterval time.Duration
+	var invalidLeaseRetention time.Duration
+	var orphanScanInterval time.Duration
+	var cleanupOrphans bool
+	var spillPodLists bool
+	var maxConcurrentReconciles int
+	var lastNodeInZoneCheck string
+	var singletonPodsCheck string
+	var localVolumesCheck string
+	var topologyLimits string
+	var maxNodesInMaintenancePercentage int
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&recordRetention, "maintenance-record-retention", controllers.DefaultRecordRetention,
+		"The time NodeMaintenanceRecords are kept after a NodeMaintenance was deleted. Zero keeps them forever.")
+	flag.StringVar(&defaultReason, "default-maintenance-reason", "",
+		"The reason set on NodeMaintenances which are created without a reason.")
+	flag.DurationVar(&defaultDrainTimeout, "default-drain-timeout", 0,
+		"The drain timeout set on NodeMaintenances which are created without a drain timeout. Zero sets none.")
+	flag.StringVar(&defaultFailurePolicy, "default-failure-policy", "",
+		"The failure policy set on NodeMaintenances which are created without a failure policy: KeepCordoned or Rollback.")
+	flag.DurationVar(&defaultPDBBypassAfter, "default-pdb-bypass-after", 0,
+		"The duration after which PodDisruptionBudgets are bypassed, set on NodeMaintenances which are created without PDB policy. "+
+			"Zero sets none, PodDisruptionBudgets are respected.")
+	flag.BoolVar(&allowMultipleMaintenances, "allow-multiple-maintenances-per-node", false,
+		"Allow several active NodeMaintenances for the same node. "+
+			"The node stays in maintenance until the last of them is deleted or set inactive.")
+	flag.DurationVar(&leaseGCInterval, "lease-gc-interval", controllers.DefaultLeaseGCInterval,
+		"The interval of deleting node leases of missing nodes and invalid node leases. Zero disables the lease garbage collection.")
+	flag.DurationVar(&invalidLeaseRetention, "invalid-lease-retention", controllers.DefaultInvalidLeaseRetention,
+		"The time invalid node leases are kept before they are deleted.")
+	flag.DurationVar(&orphanScanInterval, "orphan-scan-interval", controllers.DefaultOrphanScanInterval,
+		"The interval of scanning for nodes in maintenance without NodeMaintenance. Zero disables the scan.")
+	flag.BoolVar(&cleanupOrphans, "cleanup-orphaned-maintenances", false,
+		"Take nodes which are in maintenance without NodeMaintenance out of maintenance.")
+	flag.BoolVar(&spillPodLists, "spill-pod-lists", false,
+		"Store the complete lists of pending and evicted pods of NodeMaintenances in ConfigMaps in the operator namespace. "+
+			"The lists in the status are capped.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The maximum number of NodeMaintenances which are reconciled concurrently, i.e. nodes which are drained in parallel.")
+	flag.StringVar(&lastNodeInZoneCheck, "last-node-in-zone-check", string(nodemaintenancev1beta1.AdmissionCheckWarn),
+		"How maintenances of the last schedulable node of a zone are admitted: Ignore, Warn or Deny.")
+	flag.StringVar(&singletonPodsCheck, "singleton-pods-check", string(nodemaintenancev1beta1.AdmissionCheckWarn),
+		"How maintenances of nodes hosting the only replica of a ReplicaSet or StatefulSet are admitted: Ignore, Warn or Deny.")
+	flag.StringVar(&localVolumesCheck, "local-volumes-check", string(nodemaintenancev1beta1.AdmissionCheckWarn),
+		"How maintenances of nodes hosting pods with local persistent volumes are admitted: Ignore, Warn or Deny.")
+	flag.StringVar(&topologyLimits, "max-nodes-per-topology", "",
+		"Comma separated limits of nodes in maintenance with the same value of a label, "+
+			"in the format <label key>=<max nodes>, e.g. topology.kubernetes.io/zone=1.")
+	flag.IntVar(&maxNodesInMaintenancePercentage, "max-nodes-in-maintenance-percentage", 0,
+		"The maximum percentage of nodes of the cluster in maintenance, rounded down but at least one node. Zero disables the limit.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -83,13 +152,118 @@ func main() {
 		os.Exit(1)
 	}
 
+	// the node leases and the pod list ConfigMaps are kept in the operator namespace
+	leaseNamespace := controllers.LeaseNamespaceDefault
+	if namespace, found := os.LookupEnv("OPERATOR_NAMESPACE"); found {
+		leaseNamespace = namespace
+	}
+
+	if err = controllers.AddFieldIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to add field indexes")
+		os.Exit(1)
+	}
 	if err = (&controllers.NodeMaintenanceReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		SpillPodLists:           spillPodLists,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		LeaseNamespace:          leaseNamespace,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NodeMaintenance")
 		os.Exit(1)
 	}
+	if err = (&controllers.NodeMaintenanceRecordReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Retention: recordRetention,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NodeMaintenanceRecord")
+		os.Exit(1)
+	}
+	// the cache of the manager isn't started yet, use a direct client
+	setupClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		setupLog.Error(err, "unable to create setup client")
+		os.Exit(1)
+	}
+	if err := controllers.EnsureLeaseNamespace(context.Background(), setupClient, leaseNamespace); err != nil {
+		setupLog.Error(err, "unable to ensure lease namespace", "namespace", leaseNamespace)
+		os.Exit(1)
+	}
+
+	// the storage migrator reads the CRD and all NodeMaintenances only once, it doesn't need a cache
+	if err = (&controllers.StorageMigrator{
+		Client: setupClient,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create storage migrator")
+		os.Exit(1)
+	}
+
+	if leaseGCInterval > 0 {
+		if err = (&controllers.LeaseGarbageCollector{
+			Client:         mgr.GetClient(),
+			LeaseNamespace: leaseNamespace,
+			Interval:       leaseGCInterval,
+			Retention:      invalidLeaseRetention,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create lease garbage collector")
+			os.Exit(1)
+		}
+	}
+	if orphanScanInterval > 0 {
+		if err = (&controllers.OrphanScanner{
+			Client:         mgr.GetClient(),
+			LeaseNamespace: leaseNamespace,
+			Interval:       orphanScanInterval,
+			Cleanup:        cleanupOrphans,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create orphan scanner")
+			os.Exit(1)
+		}
+	}
+
+	webhookConfig := nodemaintenancev1beta1.WebhookConfig{
+		DefaultReason:                    defaultReason,
+		DefaultDrainTimeout:              defaultDrainTimeout,
+		DefaultPDBBypassAfter:            defaultPDBBypassAfter,
+		AllowMultipleMaintenancesPerNode: allowMultipleMaintenances,
+		LeaseNamespace:                   leaseNamespace,
+		LeaseHolderIdentity:              controllers.LeaseHolderIdentity,
+	}
+	if serviceAccount, found := os.LookupEnv("OPERATOR_SERVICE_ACCOUNT"); found {
+		webhookConfig.OperatorUsername = fmt.Sprintf("system:serviceaccount:%s:%s", leaseNamespace, serviceAccount)
+	}
+	if webhookConfig.DefaultFailurePolicy, err = nodemaintenancev1beta1.ParseDrainFailurePolicy(defaultFailurePolicy); err != nil {
+		setupLog.Error(err, "invalid default-failure-policy flag")
+		os.Exit(1)
+	}
+	if defaultDrainTimeout < 0 || defaultPDBBypassAfter < 0 {
+		setupLog.Error(fmt.Errorf("negative duration"), "invalid default-drain-timeout or default-pdb-bypass-after flag")
+		os.Exit(1)
+	}
+	for _, check := range []struct {
+		action *nodemaintenancev1beta1.AdmissionCheckAction
+		flag   string
+	}{
+		{&webhookConfig.LastNodeInZoneCheck, lastNodeInZoneCheck},
+		{&webhookConfig.SingletonPodsCheck, singletonPodsCheck},
+		{&webhookConfig.LocalVolumesCheck, localVolumesCheck},
+	} {
+		if *check.action, err = nodemaintenancev1beta1.ParseAdmissionCheckAction(check.flag); err != nil {
+			setupLog.Error(err, "invalid admission check flag")
+			os.Exit(1)
+		}
+	}
+	if webhookConfig.TopologyLimits, err = nodemaintenancev1beta1.ParseTopologyLimits(topologyLimits); err != nil {
+		setupLog.Error(err, "invalid max-nodes-per-topology flag")
+		os.Exit(1)
+	}
+	if maxNodesInMaintenancePercentage < 0 || maxNodesInMaintenancePercentage > 100 {
+		setupLog.Error(fmt.Errorf("%d isn't a percentage", maxNodesInMaintenancePercentage), "invalid max-nodes-in-maintenance-percentage flag")
+		os.Exit(1)
+	}
+	webhookConfig.MaxNodesInMaintenancePercentage = maxNodesInMaintenancePercentage
+	nodemaintenancev1beta1.SetWebhookConfig(webhookConfig)
 	if err = (&nodemaintenancev1beta1.NodeMaintenance{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "NodeMaintenance")
 		os.Exit(1)
@@ -105,10 +279,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	if namespace, found := os.LookupEnv("OPERATOR_NAMESPACE"); found {
-		controllers.SetLeaseNamespace(namespace)
-	}
-
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -116,6 +286,57 @@ func main() {
 	}
 }
 
+// runCleanup ends all maintenances, releases the node leases and removes the finalizers of all NodeMaintenances,
+// for uninstalling the operator. It returns the exit code.
+func runCleanup(args []string) int {
+	flags := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	kubeconfig := flags.String("kubeconfig", "", "Path to the kubeconfig of the cluster. Defaults to the KUBECONFIG env var, the in-cluster config and ~/.kube/config.")
+	leaseNamespace := flags.String("lease-namespace", controllers.LeaseNamespaceDefault, "The namespace of the node leases, i.e. the namespace of the operator.")
+	opts := zap.Options{
+		Development: true,
+	}
+	opts.BindFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s cleanup [flags]\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Ends all maintenances, releases the node leases and removes the finalizers of all NodeMaintenances.")
+		fmt.Fprintln(flags.Output(), "The operator must be stopped and its webhooks must be removed before.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	var config *rest.Config
+	var err error
+	if *kubeconfig != "" {
+		config, err = clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	} else {
+		config, err = ctrl.GetConfig()
+	}
+	if err != nil {
+		setupLog.Error(err, "unable to get kubeconfig")
+		return 1
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		return 1
+	}
+
+	report, err := controllers.Cleanup(context.Background(), c, config, *leaseNamespace)
+	if report != nil {
+		fmt.Printf("Nodes taken out of maintenance: %s\n", strings.Join(report.StoppedNodes, ", "))
+		fmt.Printf("Other released node leases: %s\n", strings.Join(report.ReleasedLeases, ", "))
+		fmt.Printf("NodeMaintenances without finalizer: %s\n", strings.Join(report.RemovedFinalizers, ", "))
+	}
+	if err != nil {
+		setupLog.Error(err, "cleanup failed")
+		return 1
+	}
+	return 0
+}
+
 func printVersion() {
 	setupLog.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	setupLog.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	nodemaintenancev1 "kubevirt.io/node-maintenance-operator/api/v1"
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+	"kubevirt.io/node-maintenance-operator/pkg/client/clientset/versioned/fake"
+	"kubevirt.io/node-maintenance-operator/pkg/client/informers/externalversions"
+)
+
+var _ = Describe("Generated client", func() {
+
+	var clientset *fake.Clientset
+
+	BeforeEach(func() {
+		clientset = fake.NewSimpleClientset()
+	})
+
+	It("should create and list v1beta1 NodeMaintenances", func() {
+		nm := &nodemaintenancev1beta1.NodeMaintenance{
+			ObjectMeta: metav1.ObjectMeta{Name: "nm-node01"},
+			Spec:       nodemaintenancev1beta1.NodeMaintenanceSpec{NodeName: "node01", Reason: "test"},
+		}
+		_, err := clientset.NodemaintenanceV1beta1().NodeMaintenances().Create(context.TODO(), nm, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err := clientset.NodemaintenanceV1beta1().NodeMaintenances().List(context.TODO(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Spec.NodeName).To(Equal("node01"))
+	})
+
+	It("should create and list v1 NodeMaintenances", func() {
+		nm := &nodemaintenancev1.NodeMaintenance{
+			ObjectMeta: metav1.ObjectMeta{Name: "nm-node01"},
+			Spec:       nodemaintenancev1.NodeMaintenanceSpec{NodeName: "node01", Reason: "test"},
+		}
+		_, err := clientset.NodemaintenanceV1().NodeMaintenances().Create(context.TODO(), nm, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err := clientset.NodemaintenanceV1().NodeMaintenances().List(context.TODO(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Spec.NodeName).To(Equal("node01"))
+	})
+
+	It("should list NodeMaintenances with the lister of the informer factory", func() {
+		nm := &nodemaintenancev1beta1.NodeMaintenance{
+			ObjectMeta: metav1.ObjectMeta{Name: "nm-node01"},
+			Spec:       nodemaintenancev1beta1.NodeMaintenanceSpec{NodeName: "node01"},
+		}
+		clientset = fake.NewSimpleClientset(nm)
+
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		factory := externalversions.NewSharedInformerFactory(clientset, 0)
+		lister := factory.Nodemaintenance().V1beta1().NodeMaintenances().Lister()
+		factory.Start(stopCh)
+		factory.WaitForCacheSync(stopCh)
+
+		nms, err := lister.List(labels.Everything())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nms).To(HaveLen(1))
+		Expect(nms[0].Name).To(Equal("nm-node01"))
+	})
+})
@@ -0,0 +1,183 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1 "kubevirt.io/node-maintenance-operator/api/v1"
+	scheme "kubevirt.io/node-maintenance-operator/pkg/client/clientset/versioned/scheme"
+)
+
+// NodeMaintenancesGetter has a method to return a NodeMaintenanceInterface.
+// A group's client should implement this interface.
+type NodeMaintenancesGetter interface {
+	NodeMaintenances() NodeMaintenanceInterface
+}
+
+// NodeMaintenanceInterface has methods to work with NodeMaintenance resources.
+type NodeMaintenanceInterface interface {
+	Create(ctx context.Context, nodeMaintenance *v1.NodeMaintenance, opts metav1.CreateOptions) (*v1.NodeMaintenance, error)
+	Update(ctx context.Context, nodeMaintenance *v1.NodeMaintenance, opts metav1.UpdateOptions) (*v1.NodeMaintenance, error)
+	UpdateStatus(ctx context.Context, nodeMaintenance *v1.NodeMaintenance, opts metav1.UpdateOptions) (*v1.NodeMaintenance, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.NodeMaintenance, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.NodeMaintenanceList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.NodeMaintenance, err error)
+	NodeMaintenanceExpansion
+}
+
+// nodeMaintenances implements NodeMaintenanceInterface
+type nodeMaintenances struct {
+	client rest.Interface
+}
+
+// newNodeMaintenances returns a NodeMaintenances
+func newNodeMaintenances(c *NodemaintenanceV1Client) *nodeMaintenances {
+	return &nodeMaintenances{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the nodeMaintenance, and returns the corresponding nodeMaintenance object, and an error if there is any.
+func (c *nodeMaintenances) Get(ctx context.Context, name string, options metav1.GetOptions) (result *v1.NodeMaintenance, err error) {
+	result = &v1.NodeMaintenance{}
+	err = c.client.Get().
+		Resource("nodemaintenances").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of NodeMaintenances that match those selectors.
+func (c *nodeMaintenances) List(ctx context.Context, opts metav1.ListOptions) (result *v1.NodeMaintenanceList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1.NodeMaintenanceList{}
+	err = c.client.Get().
+		Resource("nodemaintenances").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested nodeMaintenances.
+func (c *nodeMaintenances) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("nodemaintenances").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a nodeMaintenance and creates it.  Returns the server's representation of the nodeMaintenance, and an error, if there is any.
+func (c *nodeMaintenances) Create(ctx context.Context, nodeMaintenance *v1.NodeMaintenance, opts metav1.CreateOptions) (result *v1.NodeMaintenance, err error) {
+	result = &v1.NodeMaintenance{}
+	err = c.client.Post().
+		Resource("nodemaintenances").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(nodeMaintenance).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a nodeMaintenance and updates it. Returns the server's representation of the nodeMaintenance, and an error, if there is any.
+func (c *nodeMaintenances) Update(ctx context.Context, nodeMaintenance *v1.NodeMaintenance, opts metav1.UpdateOptions) (result *v1.NodeMaintenance, err error) {
+	result = &v1.NodeMaintenance{}
+	err = c.client.Put().
+		Resource("nodemaintenances").
+		Name(nodeMaintenance.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(nodeMaintenance).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *nodeMaintenances) UpdateStatus(ctx context.Context, nodeMaintenance *v1.NodeMaintenance, opts metav1.UpdateOptions) (result *v1.NodeMaintenance, err error) {
+	result = &v1.NodeMaintenance{}
+	err = c.client.Put().
+		Resource("nodemaintenances").
+		Name(nodeMaintenance.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(nodeMaintenance).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the nodeMaintenance and deletes it. Returns an error if one occurs.
+func (c *nodeMaintenances) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("nodemaintenances").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *nodeMaintenances) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("nodemaintenances").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched nodeMaintenance.
+func (c *nodeMaintenances) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.NodeMaintenance, err error) {
+	result = &v1.NodeMaintenance{}
+	err = c.client.Patch(pt).
+		Resource("nodemaintenances").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
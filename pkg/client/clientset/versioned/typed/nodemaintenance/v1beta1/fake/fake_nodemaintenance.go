@@ -0,0 +1,132 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// FakeNodeMaintenances implements NodeMaintenanceInterface
+type FakeNodeMaintenances struct {
+	Fake *FakeNodemaintenanceV1beta1
+}
+
+var nodemaintenancesResource = schema.GroupVersionResource{Group: "nodemaintenance.kubevirt.io", Version: "v1beta1", Resource: "nodemaintenances"}
+
+var nodemaintenancesKind = schema.GroupVersionKind{Group: "nodemaintenance.kubevirt.io", Version: "v1beta1", Kind: "NodeMaintenance"}
+
+// Get takes name of the nodeMaintenance, and returns the corresponding nodeMaintenance object, and an error if there is any.
+func (c *FakeNodeMaintenances) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.NodeMaintenance, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(nodemaintenancesResource, name), &v1beta1.NodeMaintenance{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.NodeMaintenance), err
+}
+
+// List takes label and field selectors, and returns the list of NodeMaintenances that match those selectors.
+func (c *FakeNodeMaintenances) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.NodeMaintenanceList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(nodemaintenancesResource, nodemaintenancesKind, opts), &v1beta1.NodeMaintenanceList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.NodeMaintenanceList{ListMeta: obj.(*v1beta1.NodeMaintenanceList).ListMeta}
+	for _, item := range obj.(*v1beta1.NodeMaintenanceList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested nodeMaintenances.
+func (c *FakeNodeMaintenances) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(nodemaintenancesResource, opts))
+}
+
+// Create takes the representation of a nodeMaintenance and creates it.  Returns the server's representation of the nodeMaintenance, and an error, if there is any.
+func (c *FakeNodeMaintenances) Create(ctx context.Context, nodeMaintenance *v1beta1.NodeMaintenance, opts v1.CreateOptions) (result *v1beta1.NodeMaintenance, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(nodemaintenancesResource, nodeMaintenance), &v1beta1.NodeMaintenance{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.NodeMaintenance), err
+}
+
+// Update takes the representation of a nodeMaintenance and updates it. Returns the server's representation of the nodeMaintenance, and an error, if there is any.
+func (c *FakeNodeMaintenances) Update(ctx context.Context, nodeMaintenance *v1beta1.NodeMaintenance, opts v1.UpdateOptions) (result *v1beta1.NodeMaintenance, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(nodemaintenancesResource, nodeMaintenance), &v1beta1.NodeMaintenance{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.NodeMaintenance), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeNodeMaintenances) UpdateStatus(ctx context.Context, nodeMaintenance *v1beta1.NodeMaintenance, opts v1.UpdateOptions) (*v1beta1.NodeMaintenance, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(nodemaintenancesResource, "status", nodeMaintenance), &v1beta1.NodeMaintenance{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.NodeMaintenance), err
+}
+
+// Delete takes name of the nodeMaintenance and deletes it. Returns an error if one occurs.
+func (c *FakeNodeMaintenances) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(nodemaintenancesResource, name), &v1beta1.NodeMaintenance{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeNodeMaintenances) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(nodemaintenancesResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.NodeMaintenanceList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched nodeMaintenance.
+func (c *FakeNodeMaintenances) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.NodeMaintenance, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(nodemaintenancesResource, name, pt, data, subresources...), &v1beta1.NodeMaintenance{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.NodeMaintenance), err
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// FakeNodeMaintenanceRecords implements NodeMaintenanceRecordInterface
+type FakeNodeMaintenanceRecords struct {
+	Fake *FakeNodemaintenanceV1beta1
+}
+
+var nodemaintenancerecordsResource = schema.GroupVersionResource{Group: "nodemaintenance.kubevirt.io", Version: "v1beta1", Resource: "nodemaintenancerecords"}
+
+var nodemaintenancerecordsKind = schema.GroupVersionKind{Group: "nodemaintenance.kubevirt.io", Version: "v1beta1", Kind: "NodeMaintenanceRecord"}
+
+// Get takes name of the nodeMaintenanceRecord, and returns the corresponding nodeMaintenanceRecord object, and an error if there is any.
+func (c *FakeNodeMaintenanceRecords) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.NodeMaintenanceRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(nodemaintenancerecordsResource, name), &v1beta1.NodeMaintenanceRecord{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.NodeMaintenanceRecord), err
+}
+
+// List takes label and field selectors, and returns the list of NodeMaintenanceRecords that match those selectors.
+func (c *FakeNodeMaintenanceRecords) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.NodeMaintenanceRecordList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(nodemaintenancerecordsResource, nodemaintenancerecordsKind, opts), &v1beta1.NodeMaintenanceRecordList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.NodeMaintenanceRecordList{ListMeta: obj.(*v1beta1.NodeMaintenanceRecordList).ListMeta}
+	for _, item := range obj.(*v1beta1.NodeMaintenanceRecordList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested nodeMaintenanceRecords.
+func (c *FakeNodeMaintenanceRecords) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(nodemaintenancerecordsResource, opts))
+}
+
+// Create takes the representation of a nodeMaintenanceRecord and creates it.  Returns the server's representation of the nodeMaintenanceRecord, and an error, if there is any.
+func (c *FakeNodeMaintenanceRecords) Create(ctx context.Context, nodeMaintenanceRecord *v1beta1.NodeMaintenanceRecord, opts v1.CreateOptions) (result *v1beta1.NodeMaintenanceRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(nodemaintenancerecordsResource, nodeMaintenanceRecord), &v1beta1.NodeMaintenanceRecord{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.NodeMaintenanceRecord), err
+}
+
+// Update takes the representation of a nodeMaintenanceRecord and updates it. Returns the server's representation of the nodeMaintenanceRecord, and an error, if there is any.
+func (c *FakeNodeMaintenanceRecords) Update(ctx context.Context, nodeMaintenanceRecord *v1beta1.NodeMaintenanceRecord, opts v1.UpdateOptions) (result *v1beta1.NodeMaintenanceRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(nodemaintenancerecordsResource, nodeMaintenanceRecord), &v1beta1.NodeMaintenanceRecord{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.NodeMaintenanceRecord), err
+}
+
+// Delete takes name of the nodeMaintenanceRecord and deletes it. Returns an error if one occurs.
+func (c *FakeNodeMaintenanceRecords) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(nodemaintenancerecordsResource, name), &v1beta1.NodeMaintenanceRecord{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeNodeMaintenanceRecords) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(nodemaintenancerecordsResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.NodeMaintenanceRecordList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched nodeMaintenanceRecord.
+func (c *FakeNodeMaintenanceRecords) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.NodeMaintenanceRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(nodemaintenancerecordsResource, name, pt, data, subresources...), &v1beta1.NodeMaintenanceRecord{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.NodeMaintenanceRecord), err
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// FakeQuorumGuards implements QuorumGuardInterface
+type FakeQuorumGuards struct {
+	Fake *FakeNodemaintenanceV1beta1
+}
+
+var quorumguardsResource = schema.GroupVersionResource{Group: "nodemaintenance.kubevirt.io", Version: "v1beta1", Resource: "quorumguards"}
+
+var quorumguardsKind = schema.GroupVersionKind{Group: "nodemaintenance.kubevirt.io", Version: "v1beta1", Kind: "QuorumGuard"}
+
+// Get takes name of the quorumGuard, and returns the corresponding quorumGuard object, and an error if there is any.
+func (c *FakeQuorumGuards) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.QuorumGuard, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(quorumguardsResource, name), &v1beta1.QuorumGuard{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.QuorumGuard), err
+}
+
+// List takes label and field selectors, and returns the list of QuorumGuards that match those selectors.
+func (c *FakeQuorumGuards) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.QuorumGuardList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(quorumguardsResource, quorumguardsKind, opts), &v1beta1.QuorumGuardList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.QuorumGuardList{ListMeta: obj.(*v1beta1.QuorumGuardList).ListMeta}
+	for _, item := range obj.(*v1beta1.QuorumGuardList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested quorumGuards.
+func (c *FakeQuorumGuards) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(quorumguardsResource, opts))
+}
+
+// Create takes the representation of a quorumGuard and creates it.  Returns the server's representation of the quorumGuard, and an error, if there is any.
+func (c *FakeQuorumGuards) Create(ctx context.Context, quorumGuard *v1beta1.QuorumGuard, opts v1.CreateOptions) (result *v1beta1.QuorumGuard, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(quorumguardsResource, quorumGuard), &v1beta1.QuorumGuard{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.QuorumGuard), err
+}
+
+// Update takes the representation of a quorumGuard and updates it. Returns the server's representation of the quorumGuard, and an error, if there is any.
+func (c *FakeQuorumGuards) Update(ctx context.Context, quorumGuard *v1beta1.QuorumGuard, opts v1.UpdateOptions) (result *v1beta1.QuorumGuard, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(quorumguardsResource, quorumGuard), &v1beta1.QuorumGuard{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.QuorumGuard), err
+}
+
+// Delete takes name of the quorumGuard and deletes it. Returns an error if one occurs.
+func (c *FakeQuorumGuards) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(quorumguardsResource, name), &v1beta1.QuorumGuard{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeQuorumGuards) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(quorumguardsResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.QuorumGuardList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched quorumGuard.
+func (c *FakeQuorumGuards) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.QuorumGuard, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(quorumguardsResource, name, pt, data, subresources...), &v1beta1.QuorumGuard{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.QuorumGuard), err
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+	scheme "kubevirt.io/node-maintenance-operator/pkg/client/clientset/versioned/scheme"
+)
+
+// NodeMaintenanceRecordsGetter has a method to return a NodeMaintenanceRecordInterface.
+// A group's client should implement this interface.
+type NodeMaintenanceRecordsGetter interface {
+	NodeMaintenanceRecords() NodeMaintenanceRecordInterface
+}
+
+// NodeMaintenanceRecordInterface has methods to work with NodeMaintenanceRecord resources.
+type NodeMaintenanceRecordInterface interface {
+	Create(ctx context.Context, nodeMaintenanceRecord *v1beta1.NodeMaintenanceRecord, opts v1.CreateOptions) (*v1beta1.NodeMaintenanceRecord, error)
+	Update(ctx context.Context, nodeMaintenanceRecord *v1beta1.NodeMaintenanceRecord, opts v1.UpdateOptions) (*v1beta1.NodeMaintenanceRecord, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.NodeMaintenanceRecord, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.NodeMaintenanceRecordList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.NodeMaintenanceRecord, err error)
+	NodeMaintenanceRecordExpansion
+}
+
+// nodeMaintenanceRecords implements NodeMaintenanceRecordInterface
+type nodeMaintenanceRecords struct {
+	client rest.Interface
+}
+
+// newNodeMaintenanceRecords returns a NodeMaintenanceRecords
+func newNodeMaintenanceRecords(c *NodemaintenanceV1beta1Client) *nodeMaintenanceRecords {
+	return &nodeMaintenanceRecords{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the nodeMaintenanceRecord, and returns the corresponding nodeMaintenanceRecord object, and an error if there is any.
+func (c *nodeMaintenanceRecords) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.NodeMaintenanceRecord, err error) {
+	result = &v1beta1.NodeMaintenanceRecord{}
+	err = c.client.Get().
+		Resource("nodemaintenancerecords").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of NodeMaintenanceRecords that match those selectors.
+func (c *nodeMaintenanceRecords) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.NodeMaintenanceRecordList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.NodeMaintenanceRecordList{}
+	err = c.client.Get().
+		Resource("nodemaintenancerecords").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested nodeMaintenanceRecords.
+func (c *nodeMaintenanceRecords) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("nodemaintenancerecords").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a nodeMaintenanceRecord and creates it.  Returns the server's representation of the nodeMaintenanceRecord, and an error, if there is any.
+func (c *nodeMaintenanceRecords) Create(ctx context.Context, nodeMaintenanceRecord *v1beta1.NodeMaintenanceRecord, opts v1.CreateOptions) (result *v1beta1.NodeMaintenanceRecord, err error) {
+	result = &v1beta1.NodeMaintenanceRecord{}
+	err = c.client.Post().
+		Resource("nodemaintenancerecords").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(nodeMaintenanceRecord).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a nodeMaintenanceRecord and updates it. Returns the server's representation of the nodeMaintenanceRecord, and an error, if there is any.
+func (c *nodeMaintenanceRecords) Update(ctx context.Context, nodeMaintenanceRecord *v1beta1.NodeMaintenanceRecord, opts v1.UpdateOptions) (result *v1beta1.NodeMaintenanceRecord, err error) {
+	result = &v1beta1.NodeMaintenanceRecord{}
+	err = c.client.Put().
+		Resource("nodemaintenancerecords").
+		Name(nodeMaintenanceRecord.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(nodeMaintenanceRecord).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the nodeMaintenanceRecord and deletes it. Returns an error if one occurs.
+func (c *nodeMaintenanceRecords) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("nodemaintenancerecords").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *nodeMaintenanceRecords) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("nodemaintenancerecords").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched nodeMaintenanceRecord.
+func (c *nodeMaintenanceRecords) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.NodeMaintenanceRecord, err error) {
+	result = &v1beta1.NodeMaintenanceRecord{}
+	err = c.client.Patch(pt).
+		Resource("nodemaintenancerecords").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+	scheme "kubevirt.io/node-maintenance-operator/pkg/client/clientset/versioned/scheme"
+)
+
+// QuorumGuardsGetter has a method to return a QuorumGuardInterface.
+// A group's client should implement this interface.
+type QuorumGuardsGetter interface {
+	QuorumGuards() QuorumGuardInterface
+}
+
+// QuorumGuardInterface has methods to work with QuorumGuard resources.
+type QuorumGuardInterface interface {
+	Create(ctx context.Context, quorumGuard *v1beta1.QuorumGuard, opts v1.CreateOptions) (*v1beta1.QuorumGuard, error)
+	Update(ctx context.Context, quorumGuard *v1beta1.QuorumGuard, opts v1.UpdateOptions) (*v1beta1.QuorumGuard, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.QuorumGuard, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.QuorumGuardList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.QuorumGuard, err error)
+	QuorumGuardExpansion
+}
+
+// quorumGuards implements QuorumGuardInterface
+type quorumGuards struct {
+	client rest.Interface
+}
+
+// newQuorumGuards returns a QuorumGuards
+func newQuorumGuards(c *NodemaintenanceV1beta1Client) *quorumGuards {
+	return &quorumGuards{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the quorumGuard, and returns the corresponding quorumGuard object, and an error if there is any.
+func (c *quorumGuards) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.QuorumGuard, err error) {
+	result = &v1beta1.QuorumGuard{}
+	err = c.client.Get().
+		Resource("quorumguards").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of QuorumGuards that match those selectors.
+func (c *quorumGuards) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.QuorumGuardList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.QuorumGuardList{}
+	err = c.client.Get().
+		Resource("quorumguards").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested quorumGuards.
+func (c *quorumGuards) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("quorumguards").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a quorumGuard and creates it.  Returns the server's representation of the quorumGuard, and an error, if there is any.
+func (c *quorumGuards) Create(ctx context.Context, quorumGuard *v1beta1.QuorumGuard, opts v1.CreateOptions) (result *v1beta1.QuorumGuard, err error) {
+	result = &v1beta1.QuorumGuard{}
+	err = c.client.Post().
+		Resource("quorumguards").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(quorumGuard).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a quorumGuard and updates it. Returns the server's representation of the quorumGuard, and an error, if there is any.
+func (c *quorumGuards) Update(ctx context.Context, quorumGuard *v1beta1.QuorumGuard, opts v1.UpdateOptions) (result *v1beta1.QuorumGuard, err error) {
+	result = &v1beta1.QuorumGuard{}
+	err = c.client.Put().
+		Resource("quorumguards").
+		Name(quorumGuard.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(quorumGuard).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the quorumGuard and deletes it. Returns an error if one occurs.
+func (c *quorumGuards) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("quorumguards").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *quorumGuards) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("quorumguards").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched quorumGuard.
+func (c *quorumGuards) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.QuorumGuard, err error) {
+	result = &v1beta1.QuorumGuard{}
+	err = c.client.Patch(pt).
+		Resource("quorumguards").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	time "time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+	nodemaintenancev1 "kubevirt.io/node-maintenance-operator/api/v1"
+	versioned "kubevirt.io/node-maintenance-operator/pkg/client/clientset/versioned"
+	internalinterfaces "kubevirt.io/node-maintenance-operator/pkg/client/informers/externalversions/internalinterfaces"
+	v1 "kubevirt.io/node-maintenance-operator/pkg/client/listers/nodemaintenance/v1"
+)
+
+// NodeMaintenanceInformer provides access to a shared informer and lister for
+// NodeMaintenances.
+type NodeMaintenanceInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1.NodeMaintenanceLister
+}
+
+type nodeMaintenanceInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewNodeMaintenanceInformer constructs a new informer for NodeMaintenance type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewNodeMaintenanceInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredNodeMaintenanceInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredNodeMaintenanceInformer constructs a new informer for NodeMaintenance type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredNodeMaintenanceInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.NodemaintenanceV1().NodeMaintenances().List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.NodemaintenanceV1().NodeMaintenances().Watch(context.TODO(), options)
+			},
+		},
+		&nodemaintenancev1.NodeMaintenance{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *nodeMaintenanceInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredNodeMaintenanceInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *nodeMaintenanceInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&nodemaintenancev1.NodeMaintenance{}, f.defaultInformer)
+}
+
+func (f *nodeMaintenanceInformer) Lister() v1.NodeMaintenanceLister {
+	return v1.NewNodeMaintenanceLister(f.Informer().GetIndexer())
+}
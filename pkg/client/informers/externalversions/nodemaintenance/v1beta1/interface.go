@@ -0,0 +1,58 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	internalinterfaces "kubevirt.io/node-maintenance-operator/pkg/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// NodeMaintenances returns a NodeMaintenanceInformer.
+	NodeMaintenances() NodeMaintenanceInformer
+	// NodeMaintenanceRecords returns a NodeMaintenanceRecordInformer.
+	NodeMaintenanceRecords() NodeMaintenanceRecordInformer
+	// QuorumGuards returns a QuorumGuardInformer.
+	QuorumGuards() QuorumGuardInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// NodeMaintenances returns a NodeMaintenanceInformer.
+func (v *version) NodeMaintenances() NodeMaintenanceInformer {
+	return &nodeMaintenanceInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// NodeMaintenanceRecords returns a NodeMaintenanceRecordInformer.
+func (v *version) NodeMaintenanceRecords() NodeMaintenanceRecordInformer {
+	return &nodeMaintenanceRecordInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// QuorumGuards returns a QuorumGuardInformer.
+func (v *version) QuorumGuards() QuorumGuardInformer {
+	return &quorumGuardInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
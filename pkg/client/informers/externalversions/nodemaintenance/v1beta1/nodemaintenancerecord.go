@@ -0,0 +1,88 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	time "time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+	nodemaintenancev1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+	versioned "kubevirt.io/node-maintenance-operator/pkg/client/clientset/versioned"
+	internalinterfaces "kubevirt.io/node-maintenance-operator/pkg/client/informers/externalversions/internalinterfaces"
+	v1beta1 "kubevirt.io/node-maintenance-operator/pkg/client/listers/nodemaintenance/v1beta1"
+)
+
+// NodeMaintenanceRecordInformer provides access to a shared informer and lister for
+// NodeMaintenanceRecords.
+type NodeMaintenanceRecordInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1beta1.NodeMaintenanceRecordLister
+}
+
+type nodeMaintenanceRecordInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewNodeMaintenanceRecordInformer constructs a new informer for NodeMaintenanceRecord type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewNodeMaintenanceRecordInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredNodeMaintenanceRecordInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredNodeMaintenanceRecordInformer constructs a new informer for NodeMaintenanceRecord type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredNodeMaintenanceRecordInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.NodemaintenanceV1beta1().NodeMaintenanceRecords().List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.NodemaintenanceV1beta1().NodeMaintenanceRecords().Watch(context.TODO(), options)
+			},
+		},
+		&nodemaintenancev1beta1.NodeMaintenanceRecord{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *nodeMaintenanceRecordInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredNodeMaintenanceRecordInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *nodeMaintenanceRecordInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&nodemaintenancev1beta1.NodeMaintenanceRecord{}, f.defaultInformer)
+}
+
+func (f *nodeMaintenanceRecordInformer) Lister() v1beta1.NodeMaintenanceRecordLister {
+	return v1beta1.NewNodeMaintenanceRecordLister(f.Informer().GetIndexer())
+}
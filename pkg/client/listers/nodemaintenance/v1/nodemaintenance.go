@@ -0,0 +1,67 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	v1 "kubevirt.io/node-maintenance-operator/api/v1"
+)
+
+// NodeMaintenanceLister helps list NodeMaintenances.
+// All objects returned here must be treated as read-only.
+type NodeMaintenanceLister interface {
+	// List lists all NodeMaintenances in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1.NodeMaintenance, err error)
+	// Get retrieves the NodeMaintenance from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1.NodeMaintenance, error)
+	NodeMaintenanceListerExpansion
+}
+
+// nodeMaintenanceLister implements the NodeMaintenanceLister interface.
+type nodeMaintenanceLister struct {
+	indexer cache.Indexer
+}
+
+// NewNodeMaintenanceLister returns a new NodeMaintenanceLister.
+func NewNodeMaintenanceLister(indexer cache.Indexer) NodeMaintenanceLister {
+	return &nodeMaintenanceLister{indexer: indexer}
+}
+
+// List lists all NodeMaintenances in the indexer.
+func (s *nodeMaintenanceLister) List(selector labels.Selector) (ret []*v1.NodeMaintenance, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1.NodeMaintenance))
+	})
+	return ret, err
+}
+
+// Get retrieves the NodeMaintenance from the index for a given name.
+func (s *nodeMaintenanceLister) Get(name string) (*v1.NodeMaintenance, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1.Resource("nodemaintenance"), name)
+	}
+	return obj.(*v1.NodeMaintenance), nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	v1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// NodeMaintenanceRecordLister helps list NodeMaintenanceRecords.
+// All objects returned here must be treated as read-only.
+type NodeMaintenanceRecordLister interface {
+	// List lists all NodeMaintenanceRecords in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.NodeMaintenanceRecord, err error)
+	// Get retrieves the NodeMaintenanceRecord from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.NodeMaintenanceRecord, error)
+	NodeMaintenanceRecordListerExpansion
+}
+
+// nodeMaintenanceRecordLister implements the NodeMaintenanceRecordLister interface.
+type nodeMaintenanceRecordLister struct {
+	indexer cache.Indexer
+}
+
+// NewNodeMaintenanceRecordLister returns a new NodeMaintenanceRecordLister.
+func NewNodeMaintenanceRecordLister(indexer cache.Indexer) NodeMaintenanceRecordLister {
+	return &nodeMaintenanceRecordLister{indexer: indexer}
+}
+
+// List lists all NodeMaintenanceRecords in the indexer.
+func (s *nodeMaintenanceRecordLister) List(selector labels.Selector) (ret []*v1beta1.NodeMaintenanceRecord, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.NodeMaintenanceRecord))
+	})
+	return ret, err
+}
+
+// Get retrieves the NodeMaintenanceRecord from the index for a given name.
+func (s *nodeMaintenanceRecordLister) Get(name string) (*v1beta1.NodeMaintenanceRecord, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("nodemaintenancerecord"), name)
+	}
+	return obj.(*v1beta1.NodeMaintenanceRecord), nil
+}
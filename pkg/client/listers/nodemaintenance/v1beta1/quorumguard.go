@@ -0,0 +1,67 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	v1beta1 "kubevirt.io/node-maintenance-operator/api/v1beta1"
+)
+
+// QuorumGuardLister helps list QuorumGuards.
+// All objects returned here must be treated as read-only.
+type QuorumGuardLister interface {
+	// List lists all QuorumGuards in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.QuorumGuard, err error)
+	// Get retrieves the QuorumGuard from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.QuorumGuard, error)
+	QuorumGuardListerExpansion
+}
+
+// quorumGuardLister implements the QuorumGuardLister interface.
+type quorumGuardLister struct {
+	indexer cache.Indexer
+}
+
+// NewQuorumGuardLister returns a new QuorumGuardLister.
+func NewQuorumGuardLister(indexer cache.Indexer) QuorumGuardLister {
+	return &quorumGuardLister{indexer: indexer}
+}
+
+// List lists all QuorumGuards in the indexer.
+func (s *quorumGuardLister) List(selector labels.Selector) (ret []*v1beta1.QuorumGuard, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.QuorumGuard))
+	})
+	return ret, err
+}
+
+// Get retrieves the QuorumGuard from the index for a given name.
+func (s *quorumGuardLister) Get(name string) (*v1beta1.QuorumGuard, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("quorumguard"), name)
+	}
+	return obj.(*v1beta1.QuorumGuard), nil
+}
@@ -0,0 +1,369 @@
+// Package lease implements the node maintenance lease protocol.
+// A node is owned exclusively by the holder of the Lease with the name of the node in the lease namespace.
+// A lease held by another holder can only be taken over after it expired.
+// The package doesn't log, failures are returned to the caller, which logs them with its own logger.
+package lease
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	kubernetes "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ApiPackage is the API group version of the Lease resource
+	ApiPackage = "coordination.k8s.io/v1"
+)
+
+// Manager acquires, renews, checks and releases node leases under a holder identity
+type Manager interface {
+	// Acquire creates the lease of the given node, or takes it over if it is held by another holder and expired.
+	// A lease which is already held is renewed when it expires within the renew margin of the Manager.
+	// Returns the due time of the held lease, or an AlreadyHeldError if the lease is held by another holder and not expired.
+	Acquire(ctx context.Context, node *corev1.Node, duration time.Duration) (time.Time, error)
+	// Renew extends the lease of the given node by the given duration and returns its new due time.
+	// Returns a NotHeldError if the lease isn't held by the holder identity of the Manager.
+	Renew(ctx context.Context, node *corev1.Node, duration time.Duration) (time.Time, error)
+	// Check returns the status of the lease of the given node, or nil if the node has no lease
+	Check(ctx context.Context, nodeName string) (*Status, error)
+	// Release invalidates the lease of the given node, if it is held by the holder identity of the Manager
+	Release(ctx context.Context, nodeName string) error
+}
+
+// Status is the state of a node lease
+type Status struct {
+	// HolderIdentity is the identity of the holder of the lease
+	HolderIdentity string
+	// AcquireTime is the time the lease was acquired by its holder, nil if the lease was released
+	AcquireTime *time.Time
+	// DueTime is the time the lease expires, zero if the lease was released
+	DueTime time.Time
+	// Valid is true if the lease is not expired and not released
+	Valid bool
+}
+
+// IsHeldBy returns true if the lease is valid and held by the given holder identity
+func (s *Status) IsHeldBy(holderIdentity string) bool {
+	return s.Valid && s.HolderIdentity == holderIdentity
+}
+
+// AlreadyHeldError is returned when a lease is held by another holder and not expired
+type AlreadyHeldError struct {
+	NodeName       string
+	HolderIdentity string
+	AcquireTime    *time.Time
+	DueTime        time.Time
+}
+
+func (e *AlreadyHeldError) Error() string {
+	return fmt.Sprintf("can't update valid lease of node %s held by different owner %s until %s", e.NodeName, e.HolderIdentity, e.DueTime.Format(time.RFC3339))
+}
+
+// NotHeldError is returned when renewing a lease which isn't held by the holder identity of the Manager
+type NotHeldError struct {
+	NodeName       string
+	HolderIdentity string
+}
+
+func (e *NotHeldError) Error() string {
+	return fmt.Sprintf("lease of node %s is not held by %s", e.NodeName, e.HolderIdentity)
+}
+
+// RenewError is returned when the update of a lease held by the holder identity of the Manager failed
+type RenewError struct {
+	NodeName string
+	Err      error
+}
+
+func (e *RenewError) Error() string {
+	return fmt.Sprintf("failed to renew owned lease of node %s: %v", e.NodeName, e.Err)
+}
+
+func (e *RenewError) Unwrap() error {
+	return e.Err
+}
+
+// IsAlreadyHeld returns true if the given error is an AlreadyHeldError
+func IsAlreadyHeld(err error) bool {
+	_, ok := err.(*AlreadyHeldError)
+	return ok
+}
+
+// IsNotHeld returns true if the given error is a NotHeldError
+func IsNotHeld(err error) bool {
+	_, ok := err.(*NotHeldError)
+	return ok
+}
+
+// IsRenewError returns true if the given error is a RenewError
+func IsRenewError(err error) bool {
+	_, ok := err.(*RenewError)
+	return ok
+}
+
+type manager struct {
+	client         client.Client
+	namespace      string
+	holderIdentity string
+	renewMargin    time.Duration
+}
+
+var _ Manager = &manager{}
+
+// NewManager returns a Manager for the leases in the given namespace, held by the given holder identity.
+// Acquire renews held leases which expire within the given renew margin.
+func NewManager(client client.Client, namespace string, holderIdentity string, renewMargin time.Duration) Manager {
+	return &manager{
+		client:         client,
+		namespace:      namespace,
+		holderIdentity: holderIdentity,
+		renewMargin:    renewMargin,
+	}
+}
+
+// IsSupported returns true if the cluster serves the Lease API
+func IsSupported(cs kubernetes.Interface) (bool, error) {
+
+	groupList, err := cs.Discovery().ServerGroups()
+	if err != nil {
+		return false, err
+	}
+	if groupList != nil {
+		apiVersions := metav1.ExtractGroupVersions(groupList)
+		for _, v := range apiVersions {
+			if v == ApiPackage {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (m *manager) Acquire(ctx context.Context, node *corev1.Node, duration time.Duration) (time.Time, error) {
+	lease, needUpdate, err := m.createOrGetExistingLease(ctx, node, duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if needUpdate {
+		now := metav1.NowMicro()
+		if err := m.updateLease(ctx, node, lease, &now, duration); err != nil {
+			return time.Time{}, err
+		}
+	}
+	// the lease reflects the written spec, no need to read it back
+	return leaseDueTime(lease), nil
+}
+
+func (m *manager) Renew(ctx context.Context, node *corev1.Node, duration time.Duration) (time.Time, error) {
+	lease, err := m.getLease(ctx, node.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return time.Time{}, &NotHeldError{NodeName: node.Name, HolderIdentity: m.holderIdentity}
+		}
+		return time.Time{}, err
+	}
+	if !m.isHolder(lease) || lease.Spec.RenewTime == nil {
+		return time.Time{}, &NotHeldError{NodeName: node.Name, HolderIdentity: m.holderIdentity}
+	}
+	now := metav1.NowMicro()
+	if err := m.writeLease(ctx, node, lease, &now, duration, lease.Spec.AcquireTime == nil); err != nil {
+		return time.Time{}, &RenewError{NodeName: node.Name, Err: err}
+	}
+	return leaseDueTime(lease), nil
+}
+
+func (m *manager) Check(ctx context.Context, nodeName string) (*Status, error) {
+	lease, err := m.getLease(ctx, nodeName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	status := &Status{
+		HolderIdentity: getHolderIdentity(lease),
+		Valid:          isValidLease(lease, time.Now()),
+	}
+	if lease.Spec.AcquireTime != nil {
+		acquireTime := lease.Spec.AcquireTime.Time
+		status.AcquireTime = &acquireTime
+	}
+	if lease.Spec.RenewTime != nil && lease.Spec.LeaseDurationSeconds != nil {
+		status.DueTime = leaseDueTime(lease)
+	}
+	return status, nil
+}
+
+func (m *manager) Release(ctx context.Context, nodeName string) error {
+	lease, err := m.getLease(ctx, nodeName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !m.isHolder(lease) {
+		return nil
+	}
+
+	lease.Spec.AcquireTime = nil
+	lease.Spec.LeaseDurationSeconds = nil
+	lease.Spec.RenewTime = nil
+	lease.Spec.LeaseTransitions = nil
+
+	return m.client.Update(ctx, lease)
+}
+
+func (m *manager) getLease(ctx context.Context, nodeName string) (*coordv1.Lease, error) {
+	lease := &coordv1.Lease{}
+	key := apitypes.NamespacedName{Namespace: m.namespace, Name: nodeName}
+	if err := m.client.Get(ctx, key, lease); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+func (m *manager) isHolder(lease *coordv1.Lease) bool {
+	return lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity == m.holderIdentity
+}
+
+func getHolderIdentity(lease *coordv1.Lease) string {
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+func makeExpectedOwnerOfLease(node *corev1.Node) *metav1.OwnerReference {
+	return &metav1.OwnerReference{
+		APIVersion: corev1.SchemeGroupVersion.WithKind("Node").Version,
+		Kind:       corev1.SchemeGroupVersion.WithKind("Node").Kind,
+		Name:       node.ObjectMeta.Name,
+		UID:        node.ObjectMeta.UID,
+	}
+}
+
+func (m *manager) createOrGetExistingLease(ctx context.Context, node *corev1.Node, duration time.Duration) (*coordv1.Lease, bool, error) {
+	holderIdentity := m.holderIdentity
+	owner := makeExpectedOwnerOfLease(node)
+	microTimeNow := metav1.NowMicro()
+
+	lease := &coordv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            node.ObjectMeta.Name,
+			Namespace:       m.namespace,
+			OwnerReferences: []metav1.OwnerReference{*owner},
+		},
+		Spec: coordv1.LeaseSpec{
+			HolderIdentity:       &holderIdentity,
+			LeaseDurationSeconds: pointer.Int32Ptr(int32(duration.Seconds())),
+			AcquireTime:          &microTimeNow,
+			RenewTime:            &microTimeNow,
+			LeaseTransitions:     pointer.Int32Ptr(0),
+		},
+	}
+
+	if err := m.client.Create(ctx, lease); err != nil {
+		if errors.IsAlreadyExists(err) {
+			existingLease, err := m.getLease(ctx, node.ObjectMeta.Name)
+			if err != nil {
+				return nil, false, err
+			}
+			return existingLease, true, nil
+		}
+		return nil, false, err
+	}
+	return lease, false, nil
+}
+
+func leaseDueTime(lease *coordv1.Lease) time.Time {
+	return lease.Spec.RenewTime.Time.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+}
+
+func (m *manager) needUpdateOwnedLease(lease *coordv1.Lease, currentTime metav1.MicroTime) (bool, bool) {
+
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true, true
+	}
+	dueTime := leaseDueTime(lease)
+
+	// if lease expired right now, then both update the lease and the acquire time (second rvalue)
+	// if the acquire time has been previously nil
+	if dueTime.Before(currentTime.Time) {
+		return true, lease.Spec.AcquireTime == nil
+	}
+
+	deadline := currentTime.Add(m.renewMargin)
+
+	// about to expire, update the lease but no the acquire time (second rvalue)
+	return dueTime.Before(deadline), false
+}
+
+func isValidLease(lease *coordv1.Lease, currentTime time.Time) bool {
+
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return false
+	}
+
+	renewTime := (*lease.Spec.RenewTime).Time
+	dueTime := leaseDueTime(lease)
+
+	// valid lease if: due time not in the past and renew time not in the future
+	return !dueTime.Before(currentTime) && !renewTime.After(currentTime)
+}
+
+func (m *manager) updateLease(ctx context.Context, node *corev1.Node, lease *coordv1.Lease, currentTime *metav1.MicroTime, duration time.Duration) error {
+
+	if m.isHolder(lease) {
+		needUpdateLease, setAcquireAndLeaseTransitions := m.needUpdateOwnedLease(lease, *currentTime)
+		if !needUpdateLease {
+			return nil
+		}
+
+		if err := m.writeLease(ctx, node, lease, currentTime, duration, setAcquireAndLeaseTransitions); err != nil {
+			return &RenewError{NodeName: node.Name, Err: err}
+		}
+		return nil
+	}
+
+	// can't update the lease if it is currently valid.
+	if isValidLease(lease, currentTime.Time) {
+		heldErr := &AlreadyHeldError{NodeName: node.Name, HolderIdentity: getHolderIdentity(lease), DueTime: leaseDueTime(lease)}
+		if lease.Spec.AcquireTime != nil {
+			acquireTime := lease.Spec.AcquireTime.Time
+			heldErr.AcquireTime = &acquireTime
+		}
+		return heldErr
+	}
+
+	return m.writeLease(ctx, node, lease, currentTime, duration, true)
+}
+
+func (m *manager) writeLease(ctx context.Context, node *corev1.Node, lease *coordv1.Lease, currentTime *metav1.MicroTime, duration time.Duration, setAcquireAndLeaseTransitions bool) error {
+	holderIdentity := m.holderIdentity
+
+	if setAcquireAndLeaseTransitions {
+		lease.Spec.AcquireTime = currentTime
+		if lease.Spec.LeaseTransitions != nil {
+			*lease.Spec.LeaseTransitions += int32(1)
+		} else {
+			lease.Spec.LeaseTransitions = pointer.Int32Ptr(1)
+		}
+	}
+	owner := makeExpectedOwnerOfLease(node)
+	lease.ObjectMeta.OwnerReferences = []metav1.OwnerReference{*owner}
+	lease.Spec.HolderIdentity = &holderIdentity
+	lease.Spec.LeaseDurationSeconds = pointer.Int32Ptr(int32(duration.Seconds()))
+	lease.Spec.RenewTime = currentTime
+	return m.client.Update(ctx, lease)
+}
@@ -0,0 +1,107 @@
+package lease
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	coordv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Lease Manager", func() {
+
+	var cl client.Client
+	var nmoManager, otherManager Manager
+
+	BeforeEach(func() {
+		cl = fake.NewClientBuilder().Build()
+		nmoManager = NewManager(cl, testNamespace, testHolderIdentity, testRenewMargin)
+		otherManager = NewManager(cl, testNamespace, "remediation", testRenewMargin)
+	})
+
+	getLease := func() *coordv1.Lease {
+		lease := &coordv1.Lease{}
+		err := cl.Get(context.TODO(), apitypes.NamespacedName{Namespace: testNamespace, Name: getMockNode().Name}, lease)
+		Expect(err).NotTo(HaveOccurred())
+		return lease
+	}
+
+	It("should acquire a new lease", func() {
+		dueTime, err := nmoManager.Acquire(context.TODO(), getMockNode(), testDuration)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dueTime).To(BeTemporally("~", time.Now().Add(testDuration), time.Minute))
+
+		status, err := nmoManager.Check(context.TODO(), getMockNode().Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.IsHeldBy(testHolderIdentity)).To(BeTrue())
+		Expect(status.AcquireTime).NotTo(BeNil())
+		Expect(status.DueTime).To(BeTemporally("~", dueTime, time.Second))
+	})
+
+	It("should not acquire a lease held by another holder", func() {
+		_, err := otherManager.Acquire(context.TODO(), getMockNode(), testDuration)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = nmoManager.Acquire(context.TODO(), getMockNode(), testDuration)
+		Expect(IsAlreadyHeld(err)).To(BeTrue())
+		Expect(err.(*AlreadyHeldError).HolderIdentity).To(Equal("remediation"))
+		Expect(err.(*AlreadyHeldError).AcquireTime).NotTo(BeNil())
+		Expect(err.(*AlreadyHeldError).DueTime).To(BeTemporally("~", time.Now().Add(testDuration), time.Minute))
+
+		_, err = nmoManager.Renew(context.TODO(), getMockNode(), testDuration)
+		Expect(IsNotHeld(err)).To(BeTrue())
+	})
+
+	It("should take over an expired lease of another holder", func() {
+		_, err := otherManager.Acquire(context.TODO(), getMockNode(), testDuration)
+		Expect(err).NotTo(HaveOccurred())
+		lease := getLease()
+		lease.Spec.RenewTime = &metav1.MicroTime{Time: time.Now().Add(-2 * testDuration)}
+		Expect(cl.Update(context.TODO(), lease)).To(Succeed())
+
+		dueTime, err := nmoManager.Acquire(context.TODO(), getMockNode(), testDuration)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dueTime).To(BeTemporally("~", time.Now().Add(testDuration), time.Minute))
+		Expect(*getLease().Spec.HolderIdentity).To(Equal(testHolderIdentity))
+		Expect(*getLease().Spec.LeaseTransitions).To(Equal(int32(1)))
+	})
+
+	It("should renew a held lease", func() {
+		acquiredDueTime, err := nmoManager.Acquire(context.TODO(), getMockNode(), testDuration)
+		Expect(err).NotTo(HaveOccurred())
+		renewTime := getLease().Spec.RenewTime
+
+		dueTime, err := nmoManager.Renew(context.TODO(), getMockNode(), 2*testDuration)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dueTime).To(BeTemporally(">", acquiredDueTime))
+		lease := getLease()
+		Expect(lease.Spec.RenewTime.Before(renewTime)).To(BeFalse())
+		Expect(*lease.Spec.LeaseDurationSeconds).To(Equal(int32((2 * testDuration).Seconds())))
+	})
+
+	It("should only release a held lease", func() {
+		_, err := otherManager.Acquire(context.TODO(), getMockNode(), testDuration)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nmoManager.Release(context.TODO(), getMockNode().Name)).To(Succeed())
+		Expect(getLease().Spec.RenewTime).NotTo(BeNil())
+
+		Expect(otherManager.Release(context.TODO(), getMockNode().Name)).To(Succeed())
+		status, err := otherManager.Check(context.TODO(), getMockNode().Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Valid).To(BeFalse())
+		Expect(status.AcquireTime).To(BeNil())
+	})
+
+	It("should report missing leases", func() {
+		status, err := nmoManager.Check(context.TODO(), getMockNode().Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(BeNil())
+		Expect(nmoManager.Release(context.TODO(), getMockNode().Name)).To(Succeed())
+	})
+})
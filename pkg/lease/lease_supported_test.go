@@ -1,4 +1,4 @@
-package controllers
+package lease
 
 import (
 	"fmt"
@@ -62,7 +62,7 @@ var _ = Describe("Leases", func() {
 			It(c.info, func() {
 				By(c.info)
 
-				isLeaseSupported, err := checkLeaseSupportedInternal(c.mock)
+				isLeaseSupported, err := IsSupported(c.mock)
 
 				ExpectEqualWithNil(err, c.expectedErr, "error should match")
 				Expect(isLeaseSupported).To(Equal(c.expectedRes))
@@ -109,7 +109,7 @@ func (self *FakeClient) Discovery() k8sdiscovery.DiscoveryInterface {
 			Groups: []metav1.APIGroup{{
 				Name: "lease",
 				Versions: []metav1.GroupVersionForDiscovery{{
-					GroupVersion: LeaseApiPackage + "notQuite",
+					GroupVersion: ApiPackage + "notQuite",
 					Version:      "v1",
 				}},
 			}},
@@ -120,7 +120,7 @@ func (self *FakeClient) Discovery() k8sdiscovery.DiscoveryInterface {
 			Groups: []metav1.APIGroup{{
 				Name: "lease",
 				Versions: []metav1.GroupVersionForDiscovery{{
-					GroupVersion: LeaseApiPackage,
+					GroupVersion: ApiPackage,
 					Version:      "v1",
 				}},
 			}},
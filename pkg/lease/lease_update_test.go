@@ -1,8 +1,7 @@
-package controllers
+package lease
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -18,6 +17,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
+const (
+	testNamespace      = "node-maintenance"
+	testHolderIdentity = "node-maintenance"
+	testDuration       = 3600 * time.Second
+	testRenewMargin    = 60 * time.Second
+)
+
 var NowTime = metav1.NowMicro()
 
 func getMockNode() *corev1.Node {
@@ -33,9 +39,9 @@ func getMockNode() *corev1.Node {
 var _ = Describe("Leases", func() {
 
 	// if current time is after this time, the lease is expired
-	leaseExpiredTime := NowTime.Add(-LeaseDuration).Add(-1 * time.Second)
+	leaseExpiredTime := NowTime.Add(-testDuration).Add(-1 * time.Second)
 	// if lease expires after this time, it should be renewed
-	renewTriggerTime := NowTime.Add(-LeaseDuration).Add(2 * DrainerTimeout)
+	renewTriggerTime := NowTime.Add(-testDuration).Add(testRenewMargin)
 
 	DescribeTable("Updates",
 		func(initialLease *coordv1.Lease, expectedLease *coordv1.Lease, expectedError error) {
@@ -44,22 +50,23 @@ var _ = Describe("Leases", func() {
 				initialLease,
 			}
 			cl := fake.NewFakeClient(objs...)
+			m := NewManager(cl, testNamespace, testHolderIdentity, testRenewMargin).(*manager)
 
-			name := apitypes.NamespacedName{Namespace: LeaseNamespace, Name: node.Name}
+			name := apitypes.NamespacedName{Namespace: testNamespace, Name: node.Name}
 			currentLease := &coordv1.Lease{}
 			err := cl.Get(context.TODO(), name, currentLease)
 			Expect(err).NotTo(HaveOccurred())
 
-			err, failedUpdateOwnedLease := updateLease(cl, node, currentLease, &NowTime, LeaseDuration)
+			err = m.updateLease(context.TODO(), node, currentLease, &NowTime, testDuration)
 
 			if expectedLease == nil {
 				Expect(err).To(HaveOccurred())
-				Expect(err).To(Equal(expectedError))
+				Expect(err).To(BeAssignableToTypeOf(expectedError))
 			} else {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(expectedError).NotTo(HaveOccurred())
 
-				Expect(failedUpdateOwnedLease).To(BeFalse())
+				Expect(IsRenewError(err)).To(BeFalse())
 				actualLease := &coordv1.Lease{}
 				err = cl.Get(context.TODO(), name, actualLease)
 				Expect(err).NotTo(HaveOccurred())
@@ -87,7 +94,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{
 						metav1.OwnerReference{
 							APIVersion: "v1",
@@ -106,13 +113,13 @@ var _ = Describe("Leases", func() {
 				},
 			},
 			nil,
-			fmt.Errorf("Can't update valid lease held by different owner"),
+			&AlreadyHeldError{},
 		),
 		Entry("update lease with different holder identity (full init)",
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{
 						metav1.OwnerReference{
 							APIVersion: "v1",
@@ -133,7 +140,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{{
 						APIVersion: corev1.SchemeGroupVersion.WithKind("Node").Version,
 						Kind:       corev1.SchemeGroupVersion.WithKind("Node").Kind,
@@ -142,8 +149,8 @@ var _ = Describe("Leases", func() {
 					}},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          &NowTime,
 					RenewTime:            &NowTime,
 					LeaseTransitions:     pointer.Int32Ptr(1),
@@ -155,7 +162,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{
 						metav1.OwnerReference{
 							APIVersion: "v1",
@@ -176,7 +183,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{{
 						APIVersion: corev1.SchemeGroupVersion.WithKind("Node").Version,
 						Kind:       corev1.SchemeGroupVersion.WithKind("Node").Kind,
@@ -185,8 +192,8 @@ var _ = Describe("Leases", func() {
 					}},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          &NowTime,
 					RenewTime:            &NowTime,
 					LeaseTransitions:     pointer.Int32Ptr(4),
@@ -198,7 +205,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{
 						metav1.OwnerReference{
 							APIVersion: "v1",
@@ -209,7 +216,7 @@ var _ = Describe("Leases", func() {
 					},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
 					LeaseDurationSeconds: nil,
 					AcquireTime:          &metav1.MicroTime{Time: NowTime.Add(-599 * time.Second)},
 					RenewTime:            nil,
@@ -219,7 +226,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{{
 						APIVersion: corev1.SchemeGroupVersion.WithKind("Node").Version,
 						Kind:       corev1.SchemeGroupVersion.WithKind("Node").Kind,
@@ -228,8 +235,8 @@ var _ = Describe("Leases", func() {
 					}},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          &NowTime,
 					RenewTime:            &NowTime,
 					LeaseTransitions:     pointer.Int32Ptr(4),
@@ -241,7 +248,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{
 						metav1.OwnerReference{
 							APIVersion: "v1",
@@ -252,8 +259,8 @@ var _ = Describe("Leases", func() {
 					},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds() - 42)),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds() - 42)),
 					AcquireTime:          nil,
 					RenewTime:            &metav1.MicroTime{Time: leaseExpiredTime},
 					LeaseTransitions:     nil,
@@ -262,7 +269,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{{
 						APIVersion: corev1.SchemeGroupVersion.WithKind("Node").Version,
 						Kind:       corev1.SchemeGroupVersion.WithKind("Node").Kind,
@@ -271,8 +278,8 @@ var _ = Describe("Leases", func() {
 					}},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          &NowTime,
 					RenewTime:            &NowTime,
 					LeaseTransitions:     pointer.Int32Ptr(1),
@@ -284,7 +291,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{
 						metav1.OwnerReference{
 							APIVersion: "v1",
@@ -295,8 +302,8 @@ var _ = Describe("Leases", func() {
 					},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          &metav1.MicroTime{Time: leaseExpiredTime},
 					RenewTime:            &metav1.MicroTime{Time: leaseExpiredTime},
 					LeaseTransitions:     pointer.Int32Ptr(1),
@@ -305,7 +312,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{{
 						APIVersion: corev1.SchemeGroupVersion.WithKind("Node").Version,
 						Kind:       corev1.SchemeGroupVersion.WithKind("Node").Kind,
@@ -314,8 +321,8 @@ var _ = Describe("Leases", func() {
 					}},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          &metav1.MicroTime{Time: leaseExpiredTime},
 					RenewTime:            &NowTime,
 					LeaseTransitions:     pointer.Int32Ptr(1),
@@ -328,7 +335,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{
 						metav1.OwnerReference{
 							APIVersion: "v1",
@@ -339,8 +346,8 @@ var _ = Describe("Leases", func() {
 					},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          nil,
 					RenewTime:            &metav1.MicroTime{Time: leaseExpiredTime},
 					LeaseTransitions:     nil,
@@ -349,7 +356,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{{
 						APIVersion: corev1.SchemeGroupVersion.WithKind("Node").Version,
 						Kind:       corev1.SchemeGroupVersion.WithKind("Node").Kind,
@@ -358,8 +365,8 @@ var _ = Describe("Leases", func() {
 					}},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          &NowTime,
 					RenewTime:            &NowTime,
 					LeaseTransitions:     pointer.Int32Ptr(1),
@@ -368,11 +375,11 @@ var _ = Describe("Leases", func() {
 			nil,
 		),
 		// TODO why not setting aquire time and transitions?
-		Entry("extend lease if same holder and lease will expire before current Time + two times the drainer timeout",
+		Entry("extend lease if same holder and lease will expire before current Time + the renew margin",
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{
 						metav1.OwnerReference{
 							APIVersion: corev1.SchemeGroupVersion.WithKind("Node").Version,
@@ -383,8 +390,8 @@ var _ = Describe("Leases", func() {
 					},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          nil,
 					RenewTime:            &metav1.MicroTime{Time: renewTriggerTime.Add(-1 * time.Second)},
 					LeaseTransitions:     nil,
@@ -393,7 +400,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{{
 						APIVersion: corev1.SchemeGroupVersion.WithKind("Node").Version,
 						Kind:       corev1.SchemeGroupVersion.WithKind("Node").Kind,
@@ -402,8 +409,8 @@ var _ = Describe("Leases", func() {
 					}},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          nil,
 					RenewTime:            &NowTime,
 					LeaseTransitions:     nil,
@@ -412,11 +419,11 @@ var _ = Describe("Leases", func() {
 			nil,
 		),
 		// TODO why not setting aquire time and transitions?
-		Entry("dont extend lease if same holder and lease not about to expire before current Time + two times the drainertimeout",
+		Entry("dont extend lease if same holder and lease not about to expire before current Time + the renew margin",
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{
 						metav1.OwnerReference{
 							APIVersion: "v1",
@@ -427,8 +434,8 @@ var _ = Describe("Leases", func() {
 					},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          nil,
 					RenewTime:            &metav1.MicroTime{Time: renewTriggerTime.Add(time.Second)},
 					LeaseTransitions:     nil,
@@ -437,7 +444,7 @@ var _ = Describe("Leases", func() {
 			&coordv1.Lease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      getMockNode().Name,
-					Namespace: LeaseNamespace,
+					Namespace: testNamespace,
 					OwnerReferences: []metav1.OwnerReference{{
 						APIVersion: "v1",
 						Kind:       "Node",
@@ -446,8 +453,8 @@ var _ = Describe("Leases", func() {
 					}},
 				},
 				Spec: coordv1.LeaseSpec{
-					HolderIdentity:       pointer.StringPtr(LeaseHolderIdentity),
-					LeaseDurationSeconds: pointer.Int32Ptr(int32(LeaseDuration.Seconds())),
+					HolderIdentity:       pointer.StringPtr(testHolderIdentity),
+					LeaseDurationSeconds: pointer.Int32Ptr(int32(testDuration.Seconds())),
 					AcquireTime:          nil,
 					RenewTime:            &metav1.MicroTime{Time: renewTriggerTime.Add(time.Second)},
 					LeaseTransitions:     nil,
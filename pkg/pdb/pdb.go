@@ -0,0 +1,104 @@
+// Package pdb reads PodDisruptionBudgets of the policy/v1 API, falling back to the policy/v1beta1 API
+// on clusters which don't serve policy/v1 yet (Kubernetes < 1.21).
+// policy/v1beta1 PodDisruptionBudgets are removed in Kubernetes 1.25.
+// PodDisruptionBudgets read from policy/v1beta1 are converted to policy/v1, so that callers only deal with one version.
+package pdb
+
+import (
+	"context"
+
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	kubernetes "k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PolicyV1Package is the API group version of the policy/v1 PodDisruptionBudget resource
+	PolicyV1Package = "policy/v1"
+)
+
+// IsPolicyV1Supported returns true if the cluster serves the policy/v1 API
+func IsPolicyV1Supported(dc discovery.DiscoveryInterface) (bool, error) {
+	groupList, err := dc.ServerGroups()
+	if err != nil {
+		return false, err
+	}
+	if groupList != nil {
+		for _, v := range metav1.ExtractGroupVersions(groupList) {
+			if v == PolicyV1Package {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Get reads the PodDisruptionBudget with the given key, from policy/v1 if policyV1 is true, otherwise from policy/v1beta1
+func Get(ctx context.Context, c client.Client, policyV1 bool, key client.ObjectKey) (*policyv1.PodDisruptionBudget, error) {
+	if policyV1 {
+		pdb := &policyv1.PodDisruptionBudget{}
+		if err := c.Get(ctx, key, pdb); err != nil {
+			return nil, err
+		}
+		return pdb, nil
+	}
+	pdb := &policyv1beta1.PodDisruptionBudget{}
+	if err := c.Get(ctx, key, pdb); err != nil {
+		return nil, err
+	}
+	return FromV1beta1(pdb), nil
+}
+
+// List lists the PodDisruptionBudgets of the given namespace, from policy/v1 if policyV1 is true, otherwise from policy/v1beta1
+func List(ctx context.Context, cs kubernetes.Interface, policyV1 bool, namespace string) ([]policyv1.PodDisruptionBudget, error) {
+	if policyV1 {
+		pdbList, err := cs.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return pdbList.Items, nil
+	}
+	pdbList, err := cs.PolicyV1beta1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pdbs := make([]policyv1.PodDisruptionBudget, 0, len(pdbList.Items))
+	for i := range pdbList.Items {
+		pdbs = append(pdbs, *FromV1beta1(&pdbList.Items[i]))
+	}
+	return pdbs, nil
+}
+
+// FromV1beta1 converts a policy/v1beta1 PodDisruptionBudget to policy/v1.
+// An empty policy/v1beta1 selector matches no pods, while an empty policy/v1 selector matches all pods,
+// so it is converted to a nil selector, which matches no pods in both versions.
+func FromV1beta1(pdb *policyv1beta1.PodDisruptionBudget) *policyv1.PodDisruptionBudget {
+	selector := pdb.Spec.Selector
+	if selector != nil && len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		selector = nil
+	}
+	return &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: policyv1.SchemeGroupVersion.String(),
+			Kind:       "PodDisruptionBudget",
+		},
+		ObjectMeta: *pdb.ObjectMeta.DeepCopy(),
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   pdb.Spec.MinAvailable,
+			Selector:       selector,
+			MaxUnavailable: pdb.Spec.MaxUnavailable,
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{
+			ObservedGeneration: pdb.Status.ObservedGeneration,
+			DisruptedPods:      pdb.Status.DisruptedPods,
+			DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+			CurrentHealthy:     pdb.Status.CurrentHealthy,
+			DesiredHealthy:     pdb.Status.DesiredHealthy,
+			ExpectedPods:       pdb.Status.ExpectedPods,
+			Conditions:         pdb.Status.Conditions,
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package pdb
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8sfakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("PodDisruptionBudgets", func() {
+
+	Context("API support", func() {
+
+		It("should detect if policy/v1 is served", func() {
+			cs := k8sfakeclient.NewSimpleClientset()
+			dc := cs.Discovery().(*fakediscovery.FakeDiscovery)
+			dc.Resources = []*metav1.APIResourceList{{GroupVersion: policyv1beta1.SchemeGroupVersion.String()}}
+			Expect(IsPolicyV1Supported(dc)).To(BeFalse())
+
+			dc.Resources = append(dc.Resources, &metav1.APIResourceList{GroupVersion: PolicyV1Package})
+			Expect(IsPolicyV1Supported(dc)).To(BeTrue())
+		})
+	})
+
+	Context("listing", func() {
+
+		It("should list policy/v1 PodDisruptionBudgets", func() {
+			cs := k8sfakeclient.NewSimpleClientset(&policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb"},
+				Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+			})
+			pdbs, err := List(context.TODO(), cs, true, "default")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pdbs).To(HaveLen(1))
+			Expect(pdbs[0].Status.DisruptionsAllowed).To(BeEquivalentTo(1))
+		})
+
+		It("should list and convert policy/v1beta1 PodDisruptionBudgets", func() {
+			cs := k8sfakeclient.NewSimpleClientset(&policyv1beta1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb"},
+				Spec: policyv1beta1.PodDisruptionBudgetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				},
+				Status: policyv1beta1.PodDisruptionBudgetStatus{DisruptionsAllowed: 2},
+			})
+			pdbs, err := List(context.TODO(), cs, false, "default")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pdbs).To(HaveLen(1))
+			Expect(pdbs[0].Name).To(Equal("pdb"))
+			Expect(pdbs[0].Spec.Selector.MatchLabels).To(HaveKeyWithValue("app", "test"))
+			Expect(pdbs[0].Status.DisruptionsAllowed).To(BeEquivalentTo(2))
+		})
+	})
+
+	Context("conversion", func() {
+
+		It("should keep an empty policy/v1beta1 selector from matching all pods", func() {
+			pdb := FromV1beta1(&policyv1beta1.PodDisruptionBudget{
+				Spec: policyv1beta1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{}},
+			})
+			Expect(pdb.Spec.Selector).To(BeNil())
+		})
+	})
+})